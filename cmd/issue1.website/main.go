@@ -1,114 +1,231 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/slim-crown/issue-1-website/internal/config"
+	"github.com/slim-crown/issue-1-website/internal/devcert"
+	boltRepo "github.com/slim-crown/issue-1-website/internal/repositories/bolt"
 	gormRepo "github.com/slim-crown/issue-1-website/internal/repositories/gorm"
+	memoryRepo "github.com/slim-crown/issue-1-website/internal/repositories/memory"
 	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"github.com/slim-crown/issue-1-website/internal/tracing"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"go.etcd.io/bbolt"
 
 	"github.com/slim-crown/issue-1-website/internal/delivery/web"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 )
 
 func main() {
+	// the deferred clean-ups, e.g. closing the database, run before exiting
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 
-	s := web.Setup{}
-
-	s.Logger = log.New(os.Stdout, "", log.Lmicroseconds|log.Lshortfile)
-
-	var err error
-	const (
-		host     = "localhost"
-		port     = "5432"
-		dbname   = "issue#1website"
-		role     = "postgres"
-		password = "password1234!@#$"
-	)
-	dataSourceName := fmt.Sprintf(
-		`host=%s port=%s dbname='%s' user='%s' password='%s' sslmode=disable`,
-		host, port, dbname, role, password)
-
-	db, err := gorm.Open("postgres", dataSourceName)
+	// settings are read from the file given by -config, ISSUE1_* environment
+	// variables and flags, see -help
+	conf, err := config.Load(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		s.Logger.Fatalf("database connection failed because: %s", err.Error())
+		log.Fatal(err)
 	}
-	defer db.Close()
-
-	{
-		if !db.HasTable(&session.Session{}) || !db.HasTable(&session.MapPair{}) {
-			errs := db.AutoMigrate(&session.Session{}, &session.MapPair{}).GetErrors()
-			if len(errs) > 0 {
-				log.Fatalf("migration of session failed becauses: %+v", errs)
-			}
+	if conf.PrintConfig {
+		if err := conf.Print(os.Stdout); err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
 
-	s.TemplatesStoragePath = "web/templates"
-	s.AssetStoragePath = "web/assets"
-	s.AssetServingRoute = "/assets/"
-
-	s.HostAddress = "localhost"
-	s.Port = "8081"
-	s.HostAddress += ":" + s.Port
+	s := web.Setup{Config: conf.WebConfig()}
 
-	s.CookieName = "I1Session"
+	logFormat, err := logging.ParseFormat(conf.Log.Format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger := logging.New(os.Stdout, logFormat)
+	if err := logger.SetLevels(conf.Log.Level); err != nil {
+		log.Fatal(err)
+	}
+	s.Logger = logger.Component("web")
+	if conf.File != "" {
+		logger.Info("settings read", logging.F("file", conf.File))
+	}
 
-	s.TokenSigningSecret = []byte("secret")
-	s.CSRFTokenLifetime = 7 * time.Minute
-	s.SessionIdleLifetime = 169 * time.Minute
-	// s.SessionIdleLifetime = 7 * 24 * time.Hour
-	s.SessionHardLifetime = 30 * 24 * time.Hour
-	s.HTTPS = false
+	sessionRepo, closeSessionRepo, err := openSessionRepo(conf)
+	if err != nil {
+		logger.Error("opening the session store failed",
+			logging.F("store", conf.Session.Store), logging.Err(err))
+		exitCode = 1
+		return
+	}
+	defer closeSessionRepo()
+	if collector, ok := sessionRepo.(session.Collector); ok {
+		collector.StartSessionGC(conf.Session.GCInterval)
+	}
 
+	restURL, err := url.Parse(conf.REST.BaseURL)
+	if err != nil {
+		logger.Error("parsing the REST base URL failed", logging.Err(err))
+		exitCode = 1
+		return
+	}
 	s.Iss1C = issue1.NewClient(
-		http.DefaultClient,
-		&url.URL{
-			Scheme: "http",
-			Host:   "localhost:8080",
-		},
-		s.Logger,
+		&http.Client{Timeout: conf.REST.Timeout},
+		restURL,
+		logger.Component("issue1"),
+	)
+	s.Iss1C.RetryPolicy = conf.RetryPolicy()
+	s.Iss1C.CircuitBreaker = conf.CircuitBreaker()
+	if conf.REST.CacheSize > 0 {
+		s.Iss1C.Cache = issue1.NewResponseCache(conf.REST.CacheSize)
+	}
+	s.Iss1C.Coalescer = issue1.NewCoalescer()
+	s.Iss1C.RateLimiter = conf.RateLimiter()
+	// trace the requests to stdout or to the given file for offline analysis
+	switch tracePath := conf.Trace; tracePath {
+	case "":
+	case "stdout":
+		s.Tracer = tracing.NewTracer(tracing.NewWriterExporter(os.Stdout))
+	default:
+		exporter, err := tracing.NewFileExporter(tracePath)
+		if err != nil {
+			logger.Error("opening trace file failed", logging.Err(err))
+			exitCode = 1
+			return
+		}
+		defer exporter.Close()
+		s.Tracer = tracing.NewTracer(exporter)
+	}
+	s.Iss1C.Use(
+		issue1.RequestIDMiddleware(),
+		tracing.Middleware(s.Tracer),
+		issue1.LoggingMiddleware(logger.Component("issue1")),
 	)
-	sessionGormRepo := gormRepo.NewSessionRepo(db)
-	s.SessionService = session.NewService(&sessionGormRepo)
+	// record the session with the REST server to be replayed in tests, the
+	// cassette being saved once the server stops
+	if cassettePath := conf.RecordCassette; cassettePath != "" {
+		cassette := issue1.NewCassette(cassettePath)
+		s.Iss1C.Use(cassette.Record())
+		defer func() {
+			if err := cassette.Save(); err != nil {
+				logger.Error("saving the cassette failed", logging.Err(err))
+				exitCode = 1
+				return
+			}
+			logger.Info("cassette saved", logging.F("file", cassettePath))
+		}()
+	}
+	s.Metrics = web.NewMetrics()
+	s.Iss1C.CallObserver = s.Metrics.ObserveRESTCall
+	sessionRepo = s.Metrics.SessionRepository(sessionRepo)
+	s.SessionService = session.NewService(&sessionRepo, logger.Component("session"))
 
 	mux := web.NewMux(&s)
 
-	go func() {
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			switch scanner.Text() {
-			case "k":
-				log.Fatalln("shutting server down...")
-			case "r":
-				err := s.ParseTemplates()
-				if err != nil {
-					log.Printf("error: template parsing failed because: %w\n warning: accessing routes now may cause fatal error.", err)
-				} else {
-					log.Printf("templates refreshed.")
-				}
-			default:
-				fmt.Println("unknown command")
-			}
-		}
-	}()
-
-	s.Logger.Println("server running...")
+	// reload the templates as they're edited during development
+	if conf.Server.WatchTemplates > 0 {
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+		go s.WatchTemplates(watchCtx, conf.Server.WatchTemplates)
+	}
 
+	srv := &http.Server{Addr: ":" + s.Port, Handler: mux}
+	servers := []*http.Server{srv}
+	serveErr := make(chan error, 2)
 	if s.HTTPS {
 		s.HostAddress = "https://" + s.HostAddress
-		log.Fatal(http.ListenAndServe(":"+s.Port, mux))
+		if conf.Server.DevTLS {
+			created, err := devcert.Ensure(conf.Server.TLSCert, conf.Server.TLSKey, []string{conf.Server.Host})
+			if err != nil {
+				logger.Error("generating a development certificate failed", logging.Err(err))
+				exitCode = 1
+				return
+			}
+			if created {
+				logger.Warn("generated a self-signed certificate for development",
+					logging.F("cert", conf.Server.TLSCert), logging.F("key", conf.Server.TLSKey))
+			}
+		}
+		if conf.Server.TLSTerminatedByProxy() {
+			go func() {
+				serveErr <- srv.ListenAndServe()
+			}()
+		} else {
+			srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			go func() {
+				serveErr <- srv.ListenAndServeTLS(conf.Server.TLSCert, conf.Server.TLSKey)
+			}()
+		}
+		if conf.Server.RedirectPort != "" {
+			redirect := &http.Server{Addr: ":" + conf.Server.RedirectPort, Handler: web.RedirectToHTTPS(&s)}
+			servers = append(servers, redirect)
+			go func() {
+				serveErr <- redirect.ListenAndServe()
+			}()
+		}
 	} else {
 		s.HostAddress = "http://" + s.HostAddress
-		log.Fatal(http.ListenAndServe(":"+s.Port, mux))
+		go func() {
+			serveErr <- srv.ListenAndServe()
+		}()
+	}
+	logger.Info("server running...", logging.F("address", s.HostAddress))
+
+	// SIGHUP reloads the templates, SIGINT and SIGTERM stop the server once
+	// the in-flight requests are served
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for {
+		select {
+		case err := <-serveErr:
+			logger.Error("server stopped", logging.Err(err))
+			exitCode = 1
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				if err := s.ParseTemplates(); err != nil {
+					logger.Error("template parsing failed, the previous templates are kept", logging.Err(err))
+				} else {
+					logger.Info("templates reloaded")
+				}
+				continue
+			}
+			logger.Info("shutting server down...",
+				logging.F("signal", sig.String()),
+				logging.F("timeout", conf.Server.ShutdownTimeout))
+			ctx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout)
+			defer cancel()
+			for _, srv := range servers {
+				if err := srv.Shutdown(ctx); err != nil {
+					logger.Error("in-flight requests didn't finish in time", logging.Err(err))
+					_ = srv.Close()
+					exitCode = 1
+				}
+			}
+			logger.Info("server stopped")
+			return
+		}
 	}
 
 	//i1 := s.Iss1C
@@ -553,3 +670,45 @@ func main() {
 		}
 	*/
 }
+
+// openSessionRepo opens the session store set by the settings, creating
+// its tables or buckets if they don't exist. closeRepo closes the store.
+func openSessionRepo(conf *config.Config) (repo session.Repository, closeRepo func() error, err error) {
+	switch conf.Session.Store {
+	case "memory":
+		return memoryRepo.NewSessionRepo(), func() error { return nil }, nil
+	case "bolt":
+		db, err := bbolt.Open(conf.Session.StorePath, 0600, &bbolt.Options{Timeout: time.Second})
+		if err != nil {
+			return nil, nil, err
+		}
+		repo, err := boltRepo.NewSessionRepo(db)
+		if err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		return repo, db.Close, nil
+	}
+
+	var db *gorm.DB
+	if conf.Session.Store == "sqlite" {
+		db, err = gorm.Open("sqlite3", conf.Session.StorePath)
+		if err == nil {
+			// SQLite doesn't allow concurrent writes
+			db.DB().SetMaxOpenConns(1)
+		}
+	} else {
+		db, err = gorm.Open("postgres", conf.DSN())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !db.HasTable(&session.Session{}) || !db.HasTable(&session.MapPair{}) {
+		errs := db.AutoMigrate(&session.Session{}, &session.MapPair{}).GetErrors()
+		if len(errs) > 0 {
+			_ = db.Close()
+			return nil, nil, fmt.Errorf("migration of session failed because: %+v", errs)
+		}
+	}
+	return gormRepo.NewSessionRepo(db), db.Close, nil
+}
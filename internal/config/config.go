@@ -0,0 +1,672 @@
+// Package config loads the settings of the website. Settings are read, from
+// the lowest to the highest precedence, from their defaults, a YAML file,
+// environment variables and command-line flags.
+//
+// Every setting has a dotted key, e.g. "database.password", which is its
+// path in the YAML file and the name of its flag. Its environment variable
+// is the key in upper case prefixed by ISSUE1_ with the dots replaced by
+// underscores, e.g. ISSUE1_DATABASE_PASSWORD.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/slim-crown/issue-1-website/internal/delivery/web"
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+// DefaultTokenSigningSecret is the default secret which is refused when
+// HTTPS is enabled.
+const DefaultTokenSigningSecret = "secret"
+
+// DefaultDatabasePassword is the password of the development database which
+// is refused when HTTPS is enabled and the sessions are stored in Postgres.
+const DefaultDatabasePassword = "password1234!@#$"
+
+// Config holds the settings of the website.
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Session  Session  `yaml:"session"`
+	Database Database `yaml:"database"`
+	REST     REST     `yaml:"rest"`
+	Log      Log      `yaml:"log"`
+	// Trace is where the trace spans are written, "stdout" or a file path.
+	// Requests aren't traced if it's empty.
+	Trace string `yaml:"trace"`
+	// RecordCassette is the path of the file the requests made to the
+	// REST server are recorded to to be replayed in tests, if any.
+	RecordCassette string `yaml:"record_cassette"`
+
+	// File is the path of the YAML file the settings were read from.
+	File string `yaml:"-"`
+	// PrintConfig is set if the settings were asked to be printed
+	// instead of running the server.
+	PrintConfig bool `yaml:"-"`
+}
+
+// Server holds the settings of the HTTP server.
+type Server struct {
+	Host              string `yaml:"host"`
+	Port              string `yaml:"port"`
+	HTTPS             bool   `yaml:"https"`
+	TemplatesPath     string `yaml:"templates_path"`
+	AssetsPath        string `yaml:"assets_path"`
+	AssetServingRoute string `yaml:"asset_serving_route"`
+	// ShutdownTimeout is how long in-flight requests are given to finish
+	// when the server is stopped.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// WatchTemplates is the interval at which the templates are checked
+	// for changes to be reloaded. They aren't watched if it's zero.
+	WatchTemplates time.Duration `yaml:"watch_templates"`
+	// AdminToken authenticates the requests to the admin routes and
+	// /metrics. They aren't served if it's empty.
+	AdminToken string `yaml:"admin_token"`
+	// TLSCert and TLSKey are the paths of the PEM encoded certificate and
+	// key used when HTTPS is set.
+	TLSCert string `yaml:"tls_cert"`
+	TLSKey  string `yaml:"tls_key"`
+	// DevTLS has a self-signed certificate generated at TLSCert and TLSKey
+	// if they don't exist. It's meant for development.
+	DevTLS bool `yaml:"dev_tls"`
+	// RedirectPort is the port plain HTTP requests are redirected to HTTPS
+	// from when HTTPS is set. They aren't if it's empty.
+	RedirectPort string `yaml:"redirect_port"`
+	// HSTSMaxAge is how long browsers are told to only use HTTPS when it's
+	// set. The Strict-Transport-Security header isn't sent if it's zero.
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age"`
+	// TrustedProxies is a comma separated list of the IP addresses or
+	// CIDR ranges of the reverse proxies whose X-Forwarded-Proto header
+	// is trusted. When HTTPS is set without TLSCert and TLSKey, they
+	// terminate TLS and the website is served to them over plain HTTP.
+	TrustedProxies string `yaml:"trusted_proxies"`
+}
+
+// TLSTerminatedByProxy reports whether the website is served over HTTPS
+// by the trusted proxies, it being served to them over plain HTTP.
+func (s Server) TLSTerminatedByProxy() bool {
+	return s.HTTPS && s.TrustedProxies != "" && s.TLSCert == "" && s.TLSKey == "" && !s.DevTLS
+}
+
+// Session holds the settings of the user sessions.
+type Session struct {
+	CookieName         string        `yaml:"cookie_name"`
+	TokenSigningSecret string        `yaml:"token_signing_secret"`
+	CSRFTokenLifetime  time.Duration `yaml:"csrf_token_lifetime"`
+	IdleLifetime       time.Duration `yaml:"idle_lifetime"`
+	HardLifetime       time.Duration `yaml:"hard_lifetime"`
+	// Store is where the sessions are stored, one of SessionStores.
+	Store string `yaml:"store"`
+	// StorePath is the file the sessions are stored in by the bolt and
+	// sqlite stores.
+	StorePath string `yaml:"store_path"`
+	// GCInterval is the interval at which expired sessions are removed
+	// from the store.
+	GCInterval time.Duration `yaml:"gc_interval"`
+}
+
+// SessionStores are the stores sessions can be kept in. The postgres store
+// is configured by the database settings.
+var SessionStores = []string{"postgres", "sqlite", "bolt", "memory"}
+
+// Database holds the settings of the Postgres database the sessions are
+// stored in when session.store is postgres.
+type Database struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Name     string `yaml:"name"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+// REST holds the settings of the issue1 REST client.
+type REST struct {
+	BaseURL string        `yaml:"base_url"`
+	Timeout time.Duration `yaml:"timeout"`
+	// CacheSize is the number of responses cached. Responses aren't
+	// cached if it's zero.
+	CacheSize int           `yaml:"cache_size"`
+	Retry     Retry         `yaml:"retry"`
+	Breaker   Breaker       `yaml:"breaker"`
+	RateLimit RESTRateLimit `yaml:"rate_limit"`
+}
+
+// Retry holds the settings of the retries of the failed idempotent
+// requests to the REST server.
+type Retry struct {
+	// MaxAttempts is the maximum number of times a request is sent, the
+	// first attempt included. Requests aren't retried if it's below 2.
+	MaxAttempts int `yaml:"max_attempts"`
+	// MinBackoff is the time waited before the first retry. It's doubled
+	// on every subsequent retry until it reaches MaxBackoff.
+	MinBackoff time.Duration `yaml:"min_backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// Breaker holds the settings of the circuit breaker which stops sending
+// the requests of an endpoint group while the REST server is failing them.
+type Breaker struct {
+	// Threshold is the number of consecutive failures after which the
+	// circuit of a group opens. There's no breaker if it's zero.
+	Threshold int `yaml:"threshold"`
+	// CoolDown is how long a circuit stays open.
+	CoolDown time.Duration `yaml:"cool_down"`
+}
+
+// RESTRateLimit holds the settings of the rate limits of the requests to
+// the REST server.
+type RESTRateLimit struct {
+	// MaxWait is how long a request may wait for the limits to let it
+	// through before it's refused.
+	MaxWait  time.Duration `yaml:"max_wait"`
+	Global   Rate          `yaml:"global"`
+	Search   Rate          `yaml:"search"`
+	Writes   Rate          `yaml:"writes"`
+	PerToken Rate          `yaml:"per_token"`
+}
+
+// Rate lets a request through every Interval with bursts of up to Burst
+// requests. There's no limit if Interval is zero.
+type Rate struct {
+	Interval time.Duration `yaml:"interval"`
+	Burst    int           `yaml:"burst"`
+}
+
+// Log holds the settings of the logger.
+type Log struct {
+	// Level is a list of levels optionally prefixed by a component as
+	// accepted by logging.Logger.SetLevels, e.g. "info,issue1=debug".
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Default returns the default settings which are fit for development.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Host:              "localhost",
+			Port:              "8081",
+			TemplatesPath:     "web/templates",
+			AssetsPath:        "web/assets",
+			AssetServingRoute: "/assets/",
+			ShutdownTimeout:   15 * time.Second,
+			HSTSMaxAge:        180 * 24 * time.Hour,
+		},
+		Session: Session{
+			CookieName:         "I1Session",
+			TokenSigningSecret: DefaultTokenSigningSecret,
+			CSRFTokenLifetime:  7 * time.Minute,
+			IdleLifetime:       169 * time.Minute,
+			HardLifetime:       30 * 24 * time.Hour,
+			Store:              "postgres",
+			GCInterval:         10 * time.Minute,
+		},
+		Database: Database{
+			Host:     "localhost",
+			Port:     "5432",
+			Name:     "issue#1website",
+			User:     "postgres",
+			Password: DefaultDatabasePassword,
+			SSLMode:  "disable",
+		},
+		REST: REST{
+			BaseURL:   "http://localhost:8080",
+			CacheSize: 1024,
+			Retry: Retry{
+				MaxAttempts: 3,
+				MinBackoff:  100 * time.Millisecond,
+				MaxBackoff:  2 * time.Second,
+			},
+			Breaker: Breaker{
+				Threshold: 5,
+				CoolDown:  30 * time.Second,
+			},
+			// the feed page bursts requests, users get 429s before
+			// REST throttles us
+			RateLimit: RESTRateLimit{
+				MaxWait:  2 * time.Second,
+				Global:   Rate{Interval: 10 * time.Millisecond, Burst: 50},
+				Search:   Rate{Interval: 100 * time.Millisecond, Burst: 10},
+				Writes:   Rate{Interval: 50 * time.Millisecond, Burst: 10},
+				PerToken: Rate{Interval: 100 * time.Millisecond, Burst: 30},
+			},
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "logfmt",
+		},
+	}
+}
+
+// setting is a single setting of the Config.
+type setting struct {
+	key    string
+	usage  string
+	value  flag.Value
+	secret bool
+}
+
+// envVar returns the environment variable of the setting.
+func (s setting) envVar() string {
+	return "ISSUE1_" + strings.ToUpper(strings.Replace(s.key, ".", "_", -1))
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "server.host", usage: "host name the website is served at", value: (*stringValue)(&c.Server.Host)},
+		{key: "server.port", usage: "port the website listens on", value: (*stringValue)(&c.Server.Port)},
+		{key: "server.https", usage: "whether the website is served over HTTPS", value: (*boolValue)(&c.Server.HTTPS)},
+		{key: "server.templates_path", usage: "directory of the templates", value: (*stringValue)(&c.Server.TemplatesPath)},
+		{key: "server.assets_path", usage: "directory of the assets", value: (*stringValue)(&c.Server.AssetsPath)},
+		{key: "server.asset_serving_route", usage: "route the assets are served under", value: (*stringValue)(&c.Server.AssetServingRoute)},
+		{key: "server.shutdown_timeout", usage: "time in-flight requests are given to finish on shutdown", value: (*durationValue)(&c.Server.ShutdownTimeout)},
+		{key: "server.watch_templates", usage: "interval the templates are checked for changes at, never if zero", value: (*durationValue)(&c.Server.WatchTemplates)},
+		{key: "server.admin_token", usage: "token authenticating the admin routes and /metrics, which are disabled if empty", value: (*stringValue)(&c.Server.AdminToken), secret: true},
+		{key: "server.tls_cert", usage: "PEM encoded TLS certificate used with HTTPS", value: (*stringValue)(&c.Server.TLSCert)},
+		{key: "server.tls_key", usage: "PEM encoded TLS key used with HTTPS", value: (*stringValue)(&c.Server.TLSKey)},
+		{key: "server.dev_tls", usage: "generate a self-signed certificate if there's none, for development", value: (*boolValue)(&c.Server.DevTLS)},
+		{key: "server.redirect_port", usage: "port plain HTTP requests are redirected to HTTPS from, none if empty", value: (*stringValue)(&c.Server.RedirectPort)},
+		{key: "server.hsts_max_age", usage: "max-age of the Strict-Transport-Security header, not sent if zero", value: (*durationValue)(&c.Server.HSTSMaxAge)},
+		{key: "server.trusted_proxies", usage: "comma separated addresses or CIDR ranges of the proxies whose X-Forwarded-Proto is trusted", value: (*stringValue)(&c.Server.TrustedProxies)},
+		{key: "session.cookie_name", usage: "name of the session cookie", value: (*stringValue)(&c.Session.CookieName)},
+		{key: "session.token_signing_secret", usage: "secret the CSRF tokens are signed with", value: (*stringValue)(&c.Session.TokenSigningSecret), secret: true},
+		{key: "session.csrf_token_lifetime", usage: "lifetime of CSRF tokens", value: (*durationValue)(&c.Session.CSRFTokenLifetime)},
+		{key: "session.idle_lifetime", usage: "lifetime of idle sessions", value: (*durationValue)(&c.Session.IdleLifetime)},
+		{key: "session.hard_lifetime", usage: "maximum lifetime of sessions", value: (*durationValue)(&c.Session.HardLifetime)},
+		{key: "session.store", usage: "where sessions are stored, " + strings.Join(SessionStores, ", "), value: (*stringValue)(&c.Session.Store)},
+		{key: "session.store_path", usage: "file sessions are stored in by the bolt and sqlite stores", value: (*stringValue)(&c.Session.StorePath)},
+		{key: "session.gc_interval", usage: "interval expired sessions are removed at", value: (*durationValue)(&c.Session.GCInterval)},
+		{key: "database.host", usage: "host of the Postgres database", value: (*stringValue)(&c.Database.Host)},
+		{key: "database.port", usage: "port of the Postgres database", value: (*stringValue)(&c.Database.Port)},
+		{key: "database.name", usage: "name of the Postgres database", value: (*stringValue)(&c.Database.Name)},
+		{key: "database.user", usage: "role used to connect to the database", value: (*stringValue)(&c.Database.User)},
+		{key: "database.password", usage: "password of the database role", value: (*stringValue)(&c.Database.Password), secret: true},
+		{key: "database.sslmode", usage: "sslmode of the database connection", value: (*stringValue)(&c.Database.SSLMode)},
+		{key: "rest.base_url", usage: "base URL of the REST server", value: (*stringValue)(&c.REST.BaseURL)},
+		{key: "rest.timeout", usage: "timeout of requests to the REST server, none if zero", value: (*durationValue)(&c.REST.Timeout)},
+		{key: "rest.cache_size", usage: "number of REST responses cached", value: (*intValue)(&c.REST.CacheSize)},
+		{key: "rest.retry.max_attempts", usage: "maximum number of attempts of idempotent requests, not retried if below 2", value: (*intValue)(&c.REST.Retry.MaxAttempts)},
+		{key: "rest.retry.min_backoff", usage: "time waited before the first retry, doubled on every retry", value: (*durationValue)(&c.REST.Retry.MinBackoff)},
+		{key: "rest.retry.max_backoff", usage: "maximum time waited before a retry", value: (*durationValue)(&c.REST.Retry.MaxBackoff)},
+		{key: "rest.breaker.threshold", usage: "consecutive failures opening the circuit of an endpoint group, no breaker if zero", value: (*intValue)(&c.REST.Breaker.Threshold)},
+		{key: "rest.breaker.cool_down", usage: "time a circuit stays open", value: (*durationValue)(&c.REST.Breaker.CoolDown)},
+		{key: "rest.rate_limit.max_wait", usage: "time a request may wait for the rate limits", value: (*durationValue)(&c.REST.RateLimit.MaxWait)},
+		{key: "rest.rate_limit.global.interval", usage: "interval requests are let through at, no limit if zero", value: (*durationValue)(&c.REST.RateLimit.Global.Interval)},
+		{key: "rest.rate_limit.global.burst", usage: "maximum burst of requests", value: (*intValue)(&c.REST.RateLimit.Global.Burst)},
+		{key: "rest.rate_limit.search.interval", usage: "interval search requests are let through at, no limit if zero", value: (*durationValue)(&c.REST.RateLimit.Search.Interval)},
+		{key: "rest.rate_limit.search.burst", usage: "maximum burst of search requests", value: (*intValue)(&c.REST.RateLimit.Search.Burst)},
+		{key: "rest.rate_limit.writes.interval", usage: "interval write requests are let through at, no limit if zero", value: (*durationValue)(&c.REST.RateLimit.Writes.Interval)},
+		{key: "rest.rate_limit.writes.burst", usage: "maximum burst of write requests", value: (*intValue)(&c.REST.RateLimit.Writes.Burst)},
+		{key: "rest.rate_limit.per_token.interval", usage: "interval the requests of a user are let through at, no limit if zero", value: (*durationValue)(&c.REST.RateLimit.PerToken.Interval)},
+		{key: "rest.rate_limit.per_token.burst", usage: "maximum burst of requests of a user", value: (*intValue)(&c.REST.RateLimit.PerToken.Burst)},
+		{key: "log.level", usage: "log levels, e.g. info,issue1=debug", value: (*stringValue)(&c.Log.Level)},
+		{key: "log.format", usage: "log format, logfmt or json", value: (*stringValue)(&c.Log.Format)},
+		{key: "trace", usage: `where trace spans are written, "stdout" or a file path`, value: (*stringValue)(&c.Trace)},
+		{key: "record_cassette", usage: "file the REST requests are recorded to", value: (*stringValue)(&c.RecordCassette)},
+	}
+}
+
+// Load returns the settings read from the YAML file given by the -config
+// flag or the ISSUE1_CONFIG environment variable, the environment variables
+// returned by getenv and the flags in args, which mustn't include the
+// program name. flag.ErrHelp is returned if the flags asked for help which
+// is written to output.
+func Load(args []string, getenv func(string) string, output io.Writer) (*Config, error) {
+	c := Default()
+	settings := c.settings()
+
+	// flags are parsed first to find the config file but only applied
+	// last so that they take precedence
+	fs := flag.NewFlagSet("issue1.website", flag.ContinueOnError)
+	fs.SetOutput(output)
+	file := fs.String("config", getenv("ISSUE1_CONFIG"), "YAML file to read the settings from")
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "print the settings with the secrets redacted and exit")
+	flags := make(map[string]*pendingValue, len(settings))
+	for _, s := range settings {
+		pending := &pendingValue{value: s.value}
+		flags[s.key] = pending
+		fs.Var(pending, s.key, s.usage+" (env "+s.envVar()+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("config: unexpected arguments %v", fs.Args())
+	}
+
+	if *file != "" {
+		b, err := ioutil.ReadFile(*file)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(b, c); err != nil {
+			return nil, fmt.Errorf("config: unable to parse %s: %w", *file, err)
+		}
+		c.File = *file
+	}
+	for _, s := range settings {
+		if v := getenv(s.envVar()); v != "" {
+			if err := s.value.Set(v); err != nil {
+				return nil, fmt.Errorf("config: invalid %s: %w", s.envVar(), err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if pending := flags[s.key]; pending.set {
+			if err := s.value.Set(pending.raw); err != nil {
+				return nil, fmt.Errorf("config: invalid -%s: %w", s.key, err)
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate reports the settings that are invalid.
+func (c *Config) Validate() error {
+	var problems []string
+	if c.Session.TokenSigningSecret == "" {
+		problems = append(problems, "session.token_signing_secret mustn't be empty")
+	} else if c.Server.HTTPS && c.Session.TokenSigningSecret == DefaultTokenSigningSecret {
+		problems = append(problems, "session.token_signing_secret mustn't be the default when server.https is set")
+	}
+	if !validPort(c.Server.Port) {
+		problems = append(problems, fmt.Sprintf("server.port %q isn't a valid port", c.Server.Port))
+	}
+	if c.Server.HTTPS && (c.Server.TLSCert == "" || c.Server.TLSKey == "") && !c.Server.TLSTerminatedByProxy() {
+		problems = append(problems, "server.tls_cert and server.tls_key are required when server.https is set, unless server.trusted_proxies terminate TLS")
+	}
+	if _, err := parseNetworks(c.Server.TrustedProxies); err != nil {
+		problems = append(problems, "server.trusted_proxies: "+err.Error())
+	}
+	if c.Server.DevTLS && !c.Server.HTTPS {
+		problems = append(problems, "server.dev_tls requires server.https")
+	}
+	if c.Server.RedirectPort != "" {
+		if !validPort(c.Server.RedirectPort) || c.Server.RedirectPort == c.Server.Port {
+			problems = append(problems, fmt.Sprintf("server.redirect_port %q isn't a valid port other than server.port", c.Server.RedirectPort))
+		} else if !c.Server.HTTPS {
+			problems = append(problems, "server.redirect_port requires server.https")
+		}
+	}
+	if c.Server.HSTSMaxAge < 0 {
+		problems = append(problems, "server.hsts_max_age mustn't be negative")
+	}
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"session.csrf_token_lifetime", c.Session.CSRFTokenLifetime},
+		{"session.idle_lifetime", c.Session.IdleLifetime},
+		{"session.hard_lifetime", c.Session.HardLifetime},
+		{"session.gc_interval", c.Session.GCInterval},
+	} {
+		if d.value <= 0 {
+			problems = append(problems, d.key+" must be positive")
+		}
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server.shutdown_timeout must be positive")
+	}
+	if c.Server.WatchTemplates < 0 {
+		problems = append(problems, "server.watch_templates mustn't be negative")
+	}
+	if c.Server.AdminToken != "" && len(c.Server.AdminToken) < 16 {
+		problems = append(problems, "server.admin_token must be at least 16 characters long")
+	}
+	if c.Server.HTTPS && c.Session.Store == "postgres" && c.Database.Password == DefaultDatabasePassword {
+		problems = append(problems, "database.password mustn't be the default when server.https is set")
+	}
+	switch c.Session.Store {
+	case "postgres", "memory":
+	case "sqlite", "bolt":
+		if c.Session.StorePath == "" {
+			problems = append(problems, "session.store_path is required when session.store is "+c.Session.Store)
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("session.store %q isn't one of %s", c.Session.Store, strings.Join(SessionStores, ", ")))
+	}
+	if c.Session.IdleLifetime > c.Session.HardLifetime {
+		problems = append(problems, "session.idle_lifetime mustn't exceed session.hard_lifetime")
+	}
+	if u, err := url.Parse(c.REST.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("rest.base_url %q isn't an http or https URL", c.REST.BaseURL))
+	}
+	if c.REST.Timeout < 0 || c.REST.CacheSize < 0 {
+		problems = append(problems, "rest.timeout and rest.cache_size mustn't be negative")
+	}
+	if r := c.REST.Retry; r.MaxAttempts > 1 && (r.MinBackoff <= 0 || r.MaxBackoff < r.MinBackoff) {
+		problems = append(problems, "rest.retry.min_backoff must be positive and not exceed rest.retry.max_backoff")
+	}
+	if b := c.REST.Breaker; b.Threshold < 0 {
+		problems = append(problems, "rest.breaker.threshold mustn't be negative")
+	} else if b.Threshold > 0 && b.CoolDown <= 0 {
+		problems = append(problems, "rest.breaker.cool_down must be positive")
+	}
+	if c.REST.RateLimit.MaxWait < 0 {
+		problems = append(problems, "rest.rate_limit.max_wait mustn't be negative")
+	}
+	for _, r := range []struct {
+		key  string
+		rate Rate
+	}{
+		{"rest.rate_limit.global", c.REST.RateLimit.Global},
+		{"rest.rate_limit.search", c.REST.RateLimit.Search},
+		{"rest.rate_limit.writes", c.REST.RateLimit.Writes},
+		{"rest.rate_limit.per_token", c.REST.RateLimit.PerToken},
+	} {
+		if r.rate.Interval < 0 || (r.rate.Interval > 0 && r.rate.Burst < 1) {
+			problems = append(problems, r.key+".interval mustn't be negative and its burst must be positive")
+		}
+	}
+	if err := logging.New(ioutil.Discard, logging.FormatLogfmt).SetLevels(c.Log.Level); err != nil {
+		problems = append(problems, "log.level: "+err.Error())
+	}
+	if _, err := logging.ParseFormat(c.Log.Format); err != nil {
+		problems = append(problems, "log.format: "+err.Error())
+	}
+	if len(problems) > 0 {
+		return errors.New("config: invalid settings:\n\t" + strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+// parseNetworks parses a comma separated list of IP addresses and CIDR
+// ranges. Addresses are taken as ranges holding only them.
+func parseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, "/") {
+			ip := net.ParseIP(field)
+			if ip == nil {
+				return nil, fmt.Errorf("%q isn't an IP address or a CIDR range", field)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(field)
+		if err != nil {
+			return nil, fmt.Errorf("%q isn't an IP address or a CIDR range", field)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func validPort(port string) bool {
+	p, err := strconv.Atoi(port)
+	return err == nil && p >= 1 && p <= 65535
+}
+
+// WebConfig returns the settings of the web handlers.
+func (c *Config) WebConfig() web.Config {
+	// the proxies were validated with the rest of the settings
+	trustedProxies, _ := parseNetworks(c.Server.TrustedProxies)
+	return web.Config{
+		TemplatesStoragePath: c.Server.TemplatesPath,
+		AssetStoragePath:     c.Server.AssetsPath,
+		AssetServingRoute:    c.Server.AssetServingRoute,
+		HostAddress:          c.Server.Host + ":" + c.Server.Port,
+		Port:                 c.Server.Port,
+		CookieName:           c.Session.CookieName,
+		CSRFTokenLifetime:    c.Session.CSRFTokenLifetime,
+		SessionIdleLifetime:  c.Session.IdleLifetime,
+		SessionHardLifetime:  c.Session.HardLifetime,
+		TokenSigningSecret:   []byte(c.Session.TokenSigningSecret),
+		HTTPS:                c.Server.HTTPS,
+		AdminToken:           c.Server.AdminToken,
+		HSTSMaxAge:           c.Server.HSTSMaxAge,
+		TrustedProxies:       trustedProxies,
+	}
+}
+
+// RetryPolicy returns the retry policy of the REST client, nil if requests
+// aren't retried.
+func (c *Config) RetryPolicy() *issue1.RetryPolicy {
+	r := c.REST.Retry
+	if r.MaxAttempts < 2 {
+		return nil
+	}
+	policy := issue1.DefaultRetryPolicy()
+	policy.MaxAttempts = r.MaxAttempts
+	policy.MinBackoff = r.MinBackoff
+	policy.MaxBackoff = r.MaxBackoff
+	return policy
+}
+
+// CircuitBreaker returns the circuit breaker of the REST client, nil if it
+// has none.
+func (c *Config) CircuitBreaker() *issue1.CircuitBreaker {
+	if c.REST.Breaker.Threshold == 0 {
+		return nil
+	}
+	return issue1.NewCircuitBreaker(c.REST.Breaker.Threshold, c.REST.Breaker.CoolDown)
+}
+
+// RateLimiter returns the rate limiter of the REST client, nil if there are
+// no limits.
+func (c *Config) RateLimiter() *issue1.RateLimiter {
+	rl := c.REST.RateLimit
+	if rl.Global.Interval == 0 && rl.Search.Interval == 0 && rl.Writes.Interval == 0 && rl.PerToken.Interval == 0 {
+		return nil
+	}
+	limiter := issue1.NewRateLimiter(issue1.Every(rl.Global.Interval, rl.Global.Burst), rl.MaxWait)
+	limiter.Groups[issue1.RateGroupSearch] = issue1.Every(rl.Search.Interval, rl.Search.Burst)
+	limiter.Groups[issue1.RateGroupWrites] = issue1.Every(rl.Writes.Interval, rl.Writes.Burst)
+	limiter.PerToken = issue1.Every(rl.PerToken.Interval, rl.PerToken.Burst)
+	return limiter
+}
+
+// DSN returns the data source name of the database.
+func (c *Config) DSN() string {
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	d := c.Database
+	return fmt.Sprintf(`host='%s' port='%s' dbname='%s' user='%s' password='%s' sslmode='%s'`,
+		quote.Replace(d.Host), quote.Replace(d.Port), quote.Replace(d.Name),
+		quote.Replace(d.User), quote.Replace(d.Password), quote.Replace(d.SSLMode))
+}
+
+// Print writes the settings as YAML with the secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	for _, s := range redacted.settings() {
+		if s.secret && s.value.String() != "" {
+			_ = s.value.Set("[REDACTED]")
+		}
+	}
+	b, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// pendingValue holds the value of a flag until it's applied.
+type pendingValue struct {
+	value flag.Value
+	raw   string
+	set   bool
+}
+
+func (p *pendingValue) String() string {
+	if p == nil || p.value == nil {
+		return ""
+	}
+	return p.value.String()
+}
+
+func (p *pendingValue) Set(raw string) error {
+	p.raw, p.set = raw, true
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value.
+func (p *pendingValue) IsBoolFlag() bool {
+	_, ok := p.value.(*boolValue)
+	return ok
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(b)
+	return nil
+}
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "issue1.yaml")
+	err := ioutil.WriteFile(file, []byte(`
+server:
+  port: "9000"
+  host: issue1.example
+session:
+  idle_lifetime: 1h
+database:
+  password: from-file
+rest:
+  base_url: http://rest.example
+  rate_limit:
+    search:
+      interval: 1s
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(
+		[]string{"-config", file, "-server.port", "9002", "-rest.cache_size=0"},
+		env(map[string]string{"ISSUE1_SERVER_PORT": "9001", "ISSUE1_DATABASE_PASSWORD": "from-env", "ISSUE1_REST_BREAKER_THRESHOLD": "0"}),
+		ioutil.Discard,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"flag over env and file", c.Server.Port, "9002"},
+		{"env over file", c.Database.Password, "from-env"},
+		{"file over default", c.Server.Host, "issue1.example"},
+		{"file duration", c.Session.IdleLifetime, time.Hour},
+		{"default", c.Session.CookieName, "I1Session"},
+		{"flag int", c.REST.CacheSize, 0},
+		{"web config", c.WebConfig().HostAddress, "issue1.example:9002"},
+		{"nested file setting", c.REST.RateLimit.Search.Interval, time.Second},
+		{"nested env setting", c.REST.Breaker.Threshold, 0},
+		{"nested default", c.REST.RateLimit.Search.Burst, 10},
+		{"retry policy", c.RetryPolicy().MaxAttempts, 3},
+		{"rate limit", c.RateLimiter().Groups[issue1.RateGroupSearch], issue1.Every(time.Second, 10)},
+		{"no circuit breaker", c.CircuitBreaker() == nil, true},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+	if c.File != file {
+		t.Errorf("File = %q, want %q", c.File, file)
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"default secret over HTTPS", []string{"-server.https"}, nil, "session.token_signing_secret"},
+		{"HTTPS without certificate", []string{"-server.https"}, nil, "server.tls_cert"},
+		{"default database password over HTTPS", []string{"-server.https"}, nil, "database.password"},
+		{"redirect to the same port", []string{"-server.redirect_port", "8081"}, nil, "server.redirect_port"},
+		{"bad port", nil, map[string]string{"ISSUE1_SERVER_PORT": "http"}, "server.port"},
+		{"bad duration", []string{"-session.hard_lifetime", "forever"}, nil, "-session.hard_lifetime"},
+		{"idle over hard lifetime", []string{"-session.idle_lifetime", "2h", "-session.hard_lifetime", "1h"}, nil, "session.idle_lifetime"},
+		{"bad base URL", nil, map[string]string{"ISSUE1_REST_BASE_URL": "localhost:8080"}, "rest.base_url"},
+		{"unknown session store", []string{"-session.store", "redis"}, nil, "session.store"},
+		{"session file store without a path", nil, map[string]string{"ISSUE1_SESSION_STORE": "bolt"}, "session.store_path"},
+		{"bad log level", []string{"-log.level", "web=loud"}, nil, "log.level"},
+		{"retry backoffs", []string{"-rest.retry.min_backoff", "3s"}, nil, "rest.retry.min_backoff"},
+		{"negative breaker threshold", []string{"-rest.breaker.threshold", "-1"}, nil, "rest.breaker.threshold"},
+		{"rate without a burst", nil, map[string]string{"ISSUE1_REST_RATE_LIMIT_WRITES_BURST": "0"}, "rest.rate_limit.writes"},
+		{"bad trusted proxy", []string{"-server.trusted_proxies", "10.0.0.1, proxy.local"}, nil, "server.trusted_proxies"},
+		{"dev certificate behind a proxy", []string{"-server.https", "-server.dev_tls", "-server.trusted_proxies", "10.0.0.1"}, nil, "server.tls_cert"},
+	} {
+		_, err := Load(tc.args, env(tc.env), ioutil.Discard)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want it to mention %s", tc.name, err, tc.want)
+		}
+	}
+
+	_, err := Load([]string{"-server.https", "-session.token_signing_secret", "s3cr3t",
+		"-server.tls_cert", "cert.pem", "-server.tls_key", "key.pem"}, env(map[string]string{"ISSUE1_DATABASE_PASSWORD": "pa55w0rd"}), ioutil.Discard)
+	if err != nil {
+		t.Errorf("a custom secret over HTTPS was rejected: %v", err)
+	}
+	_, err = Load([]string{"-server.https", "-session.token_signing_secret", "s3cr3t", "-session.store", "memory",
+		"-server.tls_cert", "cert.pem", "-server.tls_key", "key.pem"}, env(nil), ioutil.Discard)
+	if err != nil {
+		t.Errorf("the default database password was rejected without the postgres store: %v", err)
+	}
+	c, err := Load([]string{"-server.https", "-session.token_signing_secret", "s3cr3t", "-database.password", "pa55w0rd",
+		"-server.trusted_proxies", "10.0.0.1, 192.168.0.0/16"}, env(nil), ioutil.Discard)
+	if err != nil {
+		t.Errorf("HTTPS terminated by trusted proxies was rejected: %v", err)
+	} else if proxies := c.WebConfig().TrustedProxies; len(proxies) != 2 || proxies[0].String() != "10.0.0.1/32" {
+		t.Errorf("TrustedProxies = %v, want 10.0.0.1/32 and 192.168.0.0/16", proxies)
+	}
+	if _, err := Load([]string{"-help"}, env(nil), ioutil.Discard); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("-help returned %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	c, err := Load([]string{"--print-config", "-session.token_signing_secret", "s3cr3t"}, env(nil), ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.PrintConfig {
+		t.Error("PrintConfig isn't set")
+	}
+	b := new(bytes.Buffer)
+	if err := c.Print(b); err != nil {
+		t.Fatal(err)
+	}
+	printed := b.String()
+	for _, secret := range []string{"s3cr3t", Default().Database.Password} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("%q wasn't redacted:\n%s", secret, printed)
+		}
+	}
+	if !strings.Contains(printed, "hard_lifetime: 720h0m0s") {
+		t.Errorf("settings weren't printed:\n%s", printed)
+	}
+	if c.Session.TokenSigningSecret != "s3cr3t" {
+		t.Error("Print redacted the settings it was called on")
+	}
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+// adminOnly only calls the handler for requests bearing the AdminToken in
+// their Authorization header.
+func adminOnly(s *Setup, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.AdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) != 1 {
+			logger(s, r).Warn("admin request with an invalid token")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="issue1 admin"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// postReloadTemplates returns a handler for POST /admin/reload-templates
+// requests which reparses the templates from disk.
+func postReloadTemplates(s *Setup) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.ParseTemplates(); err != nil {
+			logger(s, r).Error("template parsing failed, the previous templates are kept", logging.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintf(w, "template parsing failed: %v\n", err)
+			return
+		}
+		logger(s, r).Info("templates reloaded")
+		_, _ = fmt.Fprintln(w, "templates reloaded")
+	}
+}
@@ -1,7 +1,9 @@
 package web
 
 import (
+	"errors"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 	"net/http"
 )
 
@@ -27,53 +29,47 @@ func getChannelView(s *Setup) http.HandlerFunc {
 			return
 		}
 		authToken := sess.Get(s.sessionValues.restRefreshToken)
-		channelData.posts, err = s.Iss1C.ChannelService.GetChannelPosts(channelUsername)
-		s.Logger.Printf("here2")
+		channelData.posts, err = s.Iss1C.ChannelService.GetChannelPostsContext(r.Context(), channelUsername)
 		if err != nil {
-			if err == issue1.ErrPostNotFound {
-				s.Logger.Printf("here1")
+			if errors.Is(err, issue1.ErrPostNotFound) {
 				show404Page(w, r)
 				return
 			}
-			s.Logger.Printf("here3")
-			showErrorPage(w, r)
+			logger(s, r).Warn("fetching channel posts failed", logging.F("channel", channelUsername), logging.Err(err))
+			showRESTErrorPage(s, w, r, err)
 			return
 		}
 		channelData.Releases = make([]*issue1.Release, 0)
 		//rel,err:=s.Iss1C.ChannelService.GetCatalog(channelUsername,authToken)
-		//s.Logger.Printf("here4")
 		//if err != nil {
-		//	s.Logger.Printf(err.Error())
+		//	logger(s, r).Warn("fetching catalog failed", logging.Err(err))
 		//	return
 		//}
-		//s.Logger.Printf("here46")
 		//for _, release := range rel {
 		//	channelData.Releases = append(channelData.Releases, release)
 		//}
-		//s.Logger.Printf("here90")
 		channelData.OfficialReleases = make([]*issue1.Release, 0)
 		//rel,err :=s.Iss1C.ChannelService.GetOfficialCatalog(channelUsername,authToken)
 		//if err != nil {
-		//	s.Logger.Printf(err.Error())
+		//	logger(s, r).Warn("fetching catalog failed", logging.Err(err))
 		//	return
 		//}
 		//for _, release := range rel {
 		//	channelData.OfficialReleases = append(channelData.OfficialReleases, release)
 		//}
 		channelData.Admins = make([]string, 0)
-		adm, err := s.Iss1C.ChannelService.GetAdmins(channelUsername, authToken)
+		adm, err := s.Iss1C.ChannelService.GetAdminsContext(restContext(s, sess, r), channelUsername, authToken)
 		if err != nil {
 			return
 		}
 		for _, user := range adm {
 			channelData.Admins = append(channelData.Admins, user)
 		}
-		cha, err := s.Iss1C.ChannelService.GetOwner(channelUsername, authToken)
+		cha, err := s.Iss1C.ChannelService.GetOwnerContext(restContext(s, sess, r), channelUsername, sess.Get(s.sessionValues.restRefreshToken))
 		if err != nil {
 			return
 		}
 		channelData.Owner = cha
-		s.Logger.Printf("herefinal")
-		_ = s.templates.ExecuteTemplate(w, "channel.view", channelData)
+		_ = s.executeTemplate(w, "channel.view", channelData)
 	}
 }
@@ -1,7 +1,9 @@
 package web
 
 import (
+	"errors"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 	"net/http"
 )
 
@@ -10,7 +12,7 @@ func getFront(s *Setup) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sess, err := sessionStart(s, w, r)
 		if err != nil {
-			s.Logger.Printf("server error starting session because: %v", err)
+			logger(s, r).Error("starting session failed", logging.Err(err))
 			showErrorPage(w, r)
 			return
 		}
@@ -30,7 +32,7 @@ func getFront(s *Setup) http.HandlerFunc {
 
 		err = sess.Set(s.sessionValues.csrf, token)
 		if err != nil {
-			s.Logger.Printf("server error setting value on session because: %v", err)
+			logger(s, r).Error("setting value on session failed", logging.Err(err))
 			showErrorPage(w, r)
 			return
 		}
@@ -38,7 +40,7 @@ func getFront(s *Setup) http.HandlerFunc {
 		frontForms := Input{
 			CSRF: token,
 		}
-		_ = s.templates.ExecuteTemplate(w, "front.layout", frontForms)
+		_ = s.executeTemplate(w, "front.layout", frontForms)
 	}
 }
 
@@ -51,7 +53,7 @@ func postLogin(s *Setup) http.HandlerFunc {
 		}
 		err := r.ParseForm()
 		if err != nil {
-			s.Logger.Printf("server error parsing token beccause: %v", err)
+			logger(s, r).Warn("parsing form failed", logging.Err(err))
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
 		}
@@ -61,14 +63,14 @@ func postLogin(s *Setup) http.HandlerFunc {
 
 		sess, err := sessionStart(s, w, r)
 		if err != nil {
-			s.Logger.Printf("server error starting session because: %v", err)
+			logger(s, r).Error("starting session failed", logging.Err(err))
 			showErrorPage(w, r)
 			return
 		}
 
 		valid := validCSRF(r.FormValue("_csrf"), s.TokenSigningSecret)
 		if !valid || r.FormValue("_csrf") != sess.Get(s.sessionValues.csrf) {
-			s.Logger.Printf(" login attempt with incorrect CSRF token at username %s", r.FormValue("Username"))
+			logger(s, r).Warn("login attempt with incorrect CSRF token", logging.F("username", r.FormValue("Username")))
 			loginForm.VErrors.Add("generic", "Please Try Again.")
 
 			newToken, err := cSRFToken(
@@ -83,20 +85,20 @@ func postLogin(s *Setup) http.HandlerFunc {
 
 			err = sess.Set(s.sessionValues.csrf, newToken)
 			if err != nil {
-				s.Logger.Printf("server error setting value on session because: %v", err)
+				logger(s, r).Error("setting value on session failed", logging.Err(err))
 				showErrorPage(w, r)
 				return
 			}
 			loginForm.CSRF = newToken
 
 			w.WriteHeader(http.StatusBadRequest)
-			_ = s.templates.ExecuteTemplate(w, "login.form", loginForm)
+			_ = s.executeTemplate(w, "login.form", loginForm)
 			return
 		}
 
-		restToken, err := s.Iss1C.GetAuthToken(r.FormValue("Username"), r.FormValue("Password"))
-		switch err {
-		case nil:
+		restToken, err := s.Iss1C.GetAuthTokenContext(r.Context(), r.FormValue("Username"), r.FormValue("Password"))
+		switch {
+		case err == nil:
 			// restart the session
 			err = sessionDestroy(s, w, r)
 			if err != nil {
@@ -105,7 +107,7 @@ func postLogin(s *Setup) http.HandlerFunc {
 			}
 			sess, err = sessionStart(s, w, r)
 			if err != nil {
-				s.Logger.Printf("server error starting session because: %v", err)
+				logger(s, r).Error("starting session failed", logging.Err(err))
 				showErrorPage(w, r)
 				return
 			}
@@ -120,17 +122,26 @@ func postLogin(s *Setup) http.HandlerFunc {
 				showErrorPage(w, r)
 				return
 			}
+			logging.AddFields(r.Context(), logging.F("username", r.FormValue("Username")))
+			logger(s, r).Info("user logged in")
 			http.Redirect(w, r, "/home", http.StatusSeeOther)
-		case issue1.ErrCredentialsUnaccepted:
-			s.Logger.Printf("failed login attempt at username %s", r.FormValue("Username"))
+		case errors.Is(err, issue1.ErrCredentialsUnaccepted):
+			logger(s, r).Info("failed login attempt", logging.F("username", r.FormValue("Username")))
 			loginForm.VErrors.Add("generic", "Your username or password is wrong")
 			w.WriteHeader(http.StatusUnauthorized)
-			_ = s.templates.ExecuteTemplate(w, "login.form", loginForm)
+			_ = s.executeTemplate(w, "login.form", loginForm)
+		case errors.Is(err, issue1.ErrRateLimited):
+			logger(s, r).Warn("rate limited login attempt", logging.F("username", r.FormValue("Username")))
+			loginForm.VErrors.Add("generic", "Too many attempts. Please wait a moment and try again.")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = s.executeTemplate(w, "login.form", loginForm)
+		case errors.Is(err, issue1.ErrCircuitOpen):
+			showDegradedPage(s, w, r)
 		default:
-			s.Logger.Printf("server error getting auth token beccause: %v", err)
+			logger(s, r).Error("getting auth token failed", logging.Err(err))
 			loginForm.VErrors.Add("generic", "Server Error. Please Try Again Later.")
 			w.WriteHeader(http.StatusInternalServerError)
-			_ = s.templates.ExecuteTemplate(w, "login.form", loginForm)
+			_ = s.executeTemplate(w, "login.form", loginForm)
 		}
 	}
 }
@@ -142,7 +153,7 @@ func postSignUp(s *Setup) http.HandlerFunc {
 		}
 		err := r.ParseForm()
 		if err != nil {
-			s.Logger.Printf("server error parsing token beccause: %v", err)
+			logger(s, r).Warn("parsing form failed", logging.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
@@ -153,14 +164,14 @@ func postSignUp(s *Setup) http.HandlerFunc {
 
 		sess, err := sessionStart(s, w, r)
 		if err != nil {
-			s.Logger.Printf("server error starting session because: %v", err)
+			logger(s, r).Error("starting session failed", logging.Err(err))
 			showErrorPage(w, r)
 			return
 		}
 
 		valid := validCSRF(r.FormValue("_csrf"), s.TokenSigningSecret)
 		if !valid || r.FormValue("_csrf") != sess.Get(s.sessionValues.csrf) {
-			s.Logger.Printf(" login attempt with incorrect CSRF token at username %s", r.FormValue("Username"))
+			logger(s, r).Warn("login attempt with incorrect CSRF token", logging.F("username", r.FormValue("Username")))
 			signUpForm.VErrors.Add("generic", "Please Try Again.")
 
 			newToken, err := cSRFToken(
@@ -175,14 +186,14 @@ func postSignUp(s *Setup) http.HandlerFunc {
 
 			err = sess.Set(s.sessionValues.csrf, newToken)
 			if err != nil {
-				s.Logger.Printf("server error setting value on session because: %v", err)
+				logger(s, r).Error("setting value on session failed", logging.Err(err))
 				showErrorPage(w, r)
 				return
 			}
 			signUpForm.CSRF = newToken
 
 			w.WriteHeader(http.StatusBadRequest)
-			_ = s.templates.ExecuteTemplate(w, "signup.form", signUpForm)
+			_ = s.executeTemplate(w, "signup.form", signUpForm)
 			return
 		}
 
@@ -198,7 +209,7 @@ func postSignUp(s *Setup) http.HandlerFunc {
 		// If there are any errors, redisplay the sign up form.
 		if !signUpForm.Valid() {
 			w.WriteHeader(http.StatusBadRequest)
-			_ = s.templates.ExecuteTemplate(w, "signup.form", signUpForm)
+			_ = s.executeTemplate(w, "signup.form", signUpForm)
 			return
 		}
 
@@ -210,13 +221,13 @@ func postSignUp(s *Setup) http.HandlerFunc {
 			LastName:   r.FormValue("LastName"),
 			Password:   r.FormValue("Password"),
 		}
-		user, err = s.Iss1C.UserService.AddUser(user)
-		switch err {
-		case nil:
+		user, err = s.Iss1C.UserService.AddUserContext(r.Context(), user)
+		switch {
+		case err == nil:
 			// if account creation successful, log user in.
-			restToken, err := s.Iss1C.GetAuthToken(user.Username, r.FormValue("Password"))
-			switch err {
-			case nil:
+			restToken, err := s.Iss1C.GetAuthTokenContext(r.Context(), user.Username, r.FormValue("Password"))
+			switch {
+			case err == nil:
 				// restart the session
 				err = sessionDestroy(s, w, r)
 				if err != nil {
@@ -225,7 +236,7 @@ func postSignUp(s *Setup) http.HandlerFunc {
 				}
 				sess, err = sessionStart(s, w, r)
 				if err != nil {
-					s.Logger.Printf("server error starting session because: %v", err)
+					logger(s, r).Error("starting session failed", logging.Err(err))
 					showErrorPage(w, r)
 					return
 				}
@@ -240,35 +251,56 @@ func postSignUp(s *Setup) http.HandlerFunc {
 					showErrorPage(w, r)
 					return
 				}
+				logging.AddFields(r.Context(), logging.F("username", r.FormValue("Username")))
+				logger(s, r).Info("user logged in")
 				http.Redirect(w, r, "/home", http.StatusSeeOther)
-			case issue1.ErrCredentialsUnaccepted:
-				s.Logger.Printf("failed login attempt at username %s", r.FormValue("Username"))
+			case errors.Is(err, issue1.ErrCredentialsUnaccepted):
+				logger(s, r).Info("failed login attempt", logging.F("username", r.FormValue("Username")))
 				signUpForm.VErrors.Add("generic", "Success. Try logging in.")
 				w.WriteHeader(http.StatusUnauthorized)
-				_ = s.templates.ExecuteTemplate(w, "signup.form", signUpForm)
+				_ = s.executeTemplate(w, "signup.form", signUpForm)
 			default:
-				s.Logger.Printf("server error getting auth token beccause: %v", err)
+				logger(s, r).Error("getting auth token failed", logging.Err(err))
 				//showErrorPage(w,r)
 				signUpForm.VErrors.Add("generic", "Success. Try logging in.")
 				w.WriteHeader(http.StatusInternalServerError)
-				_ = s.templates.ExecuteTemplate(w, "signup.form", signUpForm)
+				_ = s.executeTemplate(w, "signup.form", signUpForm)
 			}
-		case issue1.ErrUserNameOccupied:
-			s.Logger.Printf("signup attempt on a occupied username")
+		case errors.Is(err, issue1.ErrUserNameOccupied):
+			logger(s, r).Info("signup attempt on an occupied username", logging.F("username", r.FormValue("Username")))
 			//showErrorPage(w,r)
 			signUpForm.VErrors.Add("Username", "Username is occupied.")
 			w.WriteHeader(http.StatusInternalServerError)
-			_ = s.templates.ExecuteTemplate(w, "signup.form", signUpForm)
-		case issue1.ErrEmailIsOccupied:
-			s.Logger.Printf("signup attempt on a occupied email")
+			_ = s.executeTemplate(w, "signup.form", signUpForm)
+		case errors.Is(err, issue1.ErrEmailIsOccupied):
+			logger(s, r).Info("signup attempt on an occupied email")
 			signUpForm.VErrors.Add("Email", "Email is occupied.")
 			w.WriteHeader(http.StatusInternalServerError)
-			_ = s.templates.ExecuteTemplate(w, "signup.form", signUpForm)
+			_ = s.executeTemplate(w, "signup.form", signUpForm)
+		case errors.Is(err, issue1.ErrInvalidData):
+			logger(s, r).Info("signup attempt with data rejected by REST", logging.Err(err))
+			var vErrs issue1.ValidationErrors
+			var apiErr *issue1.APIError
+			if errors.As(err, &vErrs) {
+				for field, msgs := range vErrs {
+					for _, msg := range msgs {
+						signUpForm.VErrors.Add(formFieldOf(field), msg)
+					}
+				}
+			} else if errors.As(err, &apiErr) && apiErr.Reason != "" {
+				signUpForm.VErrors.Add(formFieldOf(apiErr.Reason), apiErr.Message)
+			} else {
+				signUpForm.VErrors.Add("generic", "Please check your input and try again.")
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			_ = s.executeTemplate(w, "signup.form", signUpForm)
+		case errors.Is(err, issue1.ErrCircuitOpen):
+			showDegradedPage(s, w, r)
 		default:
-			s.Logger.Printf("server error getting auth token beccause: %v", err)
+			logger(s, r).Error("getting auth token failed", logging.Err(err))
 			signUpForm.VErrors.Add("generic", "Server Error. Please Try Again Later.")
 			w.WriteHeader(http.StatusInternalServerError)
-			_ = s.templates.ExecuteTemplate(w, "login.form", signUpForm)
+			_ = s.executeTemplate(w, "login.form", signUpForm)
 		}
 	}
 }
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPostLogin(t *testing.T) {
+	_, website, client := newTestWebsite(t)
+
+	for _, tc := range []struct {
+		name       string
+		csrf       string
+		password   string
+		status     int
+		containing string
+	}{
+		{"wrong CSRF token", "forged", loveless.Password, http.StatusBadRequest, "Please Try Again."},
+		{"wrong password", "", "hunter2", http.StatusUnauthorized, "Your username or password is wrong"},
+	} {
+		csrf := tc.csrf
+		if csrf == "" {
+			csrf = frontCSRF(t, website, client)
+		}
+		resp := postLoginForm(t, website, client, csrf, loveless.Username, tc.password)
+		body := readBody(t, resp)
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: status = %d, want %d", tc.name, resp.StatusCode, tc.status)
+		}
+		if !strings.Contains(body, tc.containing) {
+			t.Errorf("%s: login form doesn't contain %q:\n%s", tc.name, tc.containing, body)
+		}
+	}
+
+	login(t, website, client)
+	// logged in users are sent home from the front page
+	resp := get(t, client, website.URL+"/")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/home" {
+		t.Errorf("front page: %d to %q, want a redirect to /home", resp.StatusCode, resp.Header.Get("Location"))
+	}
+}
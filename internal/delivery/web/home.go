@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
 	"net/http"
 )
@@ -19,7 +20,7 @@ func getHome(s *Setup) http.HandlerFunc {
 		if err != nil {
 			return
 		}
-		_ = s.templates.ExecuteTemplate(w, "home", homeData)
+		_ = s.executeTemplate(w, "home", homeData)
 	}
 }
 
@@ -49,22 +50,14 @@ func postFeedPosts(s *Setup) http.HandlerFunc {
 		postList := make([]augmentedPost, 0)
 		username := sess.Get(s.sessionValues.username)
 		authToken := sess.Get(s.sessionValues.restRefreshToken)
-		posts, err := s.Iss1C.FeedService.GetFeedPostsPaged(p.Page, p.PerPage, p.Sorting, username, authToken)
+		posts, err := s.Iss1C.FeedService.GetFeedPostsPagedContext(restContext(s, sess, r), p.Page, p.PerPage, p.Sorting, username, authToken)
 		if err != nil {
-			if err == issue1.ErrAccessDenied {
-				err = refreshTokenAuthOnSession(sess, s, w, r)
-				if err != nil {
-					return
-				}
-				posts, err = s.Iss1C.FeedService.GetFeedPostsPaged(p.Page, p.PerPage, p.Sorting, username, sess.Get(s.sessionValues.restRefreshToken))
-				if err != nil {
-					showErrorPage(w, r)
-					return
-				}
-			} else {
-				showErrorPage(w, r)
+			if errors.Is(err, issue1.ErrAccessDenied) {
+				http.Redirect(w, r, "/", http.StatusSeeOther)
 				return
 			}
+			showRESTErrorPage(s, w, r, err)
+			return
 		}
 
 		for _, p := range posts {
@@ -73,21 +66,12 @@ func postFeedPosts(s *Setup) http.HandlerFunc {
 			//	showErrorPage(w, r)
 			//	return
 			//}
-			releases := make([]*issue1.Release, 0)
-			for _, id := range p.ContentsID {
-				rel, err := s.Iss1C.ReleaseService.GetRelease(id)
-				if err != nil {
-					showErrorPage(w, r)
-					return
-				}
-				releases = append(releases, rel)
-			}
 			postList = append(postList, augmentedPost{
 				Post:     p,
-				Releases: releases,
+				Releases: getReleases(s, r, p.ContentsID),
 			})
 		}
 
-		_ = s.templates.ExecuteTemplate(w, "post.list", postList)
+		_ = s.executeTemplate(w, "post.list", postList)
 	}
 }
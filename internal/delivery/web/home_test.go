@@ -0,0 +1,34 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+func TestGetHome(t *testing.T) {
+	rest, website, client := newTestWebsite(t)
+	rest.AddChannel(issue1.Channel{ChannelUsername: "chromagnum", Name: "Chromagnum"})
+	rest.Subscribe(loveless.Username, "chromagnum")
+
+	resp := get(t, client, website.URL+"/home")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/" {
+		t.Errorf("logged out: %d to %q, want a redirect to /", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	login(t, website, client)
+	resp = get(t, client, website.URL+"/home")
+	body := readBody(t, resp)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d:\n%s", resp.StatusCode, http.StatusOK, body)
+	}
+	// the navbar shows the user and their subscriptions
+	for _, want := range []string{loveless.Username, "Chromagnum"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("home page doesn't contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/internal/metrics"
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"github.com/slim-crown/issue-1-website/internal/tracing"
+)
+
+// Metrics holds the telemetry of the website served at /metrics.
+type Metrics struct {
+	Registry *metrics.Registry
+
+	requests        *metrics.CounterVec
+	requestDuration *metrics.HistogramVec
+	restCalls       *metrics.CounterVec
+	restErrors      *metrics.CounterVec
+	restDuration    *metrics.HistogramVec
+	sessionDuration *metrics.HistogramVec
+	renderDuration  *metrics.HistogramVec
+}
+
+// NewMetrics returns Metrics registered with a new registry.
+func NewMetrics() *Metrics {
+	r := metrics.NewRegistry()
+	return &Metrics{
+		Registry: r,
+		requests: r.NewCounterVec("issue1_http_requests_total",
+			"Requests served per route, method and status code.",
+			"route", "method", "status"),
+		requestDuration: r.NewHistogramVec("issue1_http_request_duration_seconds",
+			"Time taken to serve requests per route and method.",
+			nil, "route", "method"),
+		restCalls: r.NewCounterVec("issue1_rest_calls_total",
+			"Calls made to the REST server per service method.",
+			"endpoint"),
+		restErrors: r.NewCounterVec("issue1_rest_call_errors_total",
+			"Calls made to the REST server that failed per service method.",
+			"endpoint"),
+		restDuration: r.NewHistogramVec("issue1_rest_call_duration_seconds",
+			"Time taken by calls to the REST server per service method.",
+			nil, "endpoint"),
+		sessionDuration: r.NewHistogramVec("issue1_session_store_duration_seconds",
+			"Time taken by session store operations.",
+			nil, "op"),
+		renderDuration: r.NewHistogramVec("issue1_template_render_duration_seconds",
+			"Time taken to render templates.",
+			nil, "template"),
+	}
+}
+
+// handler returns a handler recording the requests served by next under the
+// given route. next is returned as is if m is nil.
+func (m *Metrics) handler(method, route string, next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := tracing.NewResponseRecorder(w)
+		next.ServeHTTP(rec, r)
+		m.requests.Inc(route, method, strconv.Itoa(rec.Status))
+		m.requestDuration.Observe(time.Since(start).Seconds(), route, method)
+	})
+}
+
+// ObserveRESTCall records a call made to the REST server. It's meant to be
+// used as the CallObserver of the issue1.Client.
+func (m *Metrics) ObserveRESTCall(endpoint string, d time.Duration, err error) {
+	m.restCalls.Inc(endpoint)
+	m.restDuration.Observe(d.Seconds(), endpoint)
+	if err != nil {
+		m.restErrors.Inc(endpoint)
+	}
+}
+
+// observeRender records the time taken to render the named template. It does
+// nothing if m is nil.
+func (m *Metrics) observeRender(name string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.renderDuration.Observe(d.Seconds(), name)
+}
+
+// SessionRepository returns a session.Repository timing the operations of
+// repo. If repo is a session.Counter, the number of active sessions is
+// reported as well. It must be called at most once.
+func (m *Metrics) SessionRepository(repo session.Repository) session.Repository {
+	if counter, ok := repo.(session.Counter); ok {
+		m.Registry.NewGaugeFunc("issue1_active_sessions",
+			"Sessions that haven't expired.",
+			func() float64 {
+				count, errs := counter.CountSessions()
+				if len(errs) > 0 {
+					return math.NaN()
+				}
+				return float64(count)
+			})
+	}
+	return &timedSessionRepo{repo: repo, duration: m.sessionDuration}
+}
+
+// timedSessionRepo times the operations of a session.Repository.
+type timedSessionRepo struct {
+	repo     session.Repository
+	duration *metrics.HistogramVec
+}
+
+func (t *timedSessionRepo) observe(op string, start time.Time) {
+	t.duration.Observe(time.Since(start).Seconds(), op)
+}
+
+func (t *timedSessionRepo) GetSession(sessionID string) (*session.Session, []error) {
+	defer t.observe("get", time.Now())
+	return t.repo.GetSession(sessionID)
+}
+
+func (t *timedSessionRepo) AddSession(sess *session.Session) (*session.Session, []error) {
+	defer t.observe("add", time.Now())
+	return t.repo.AddSession(sess)
+}
+
+func (t *timedSessionRepo) UpdateSession(sess *session.Session) (*session.Session, []error) {
+	defer t.observe("update", time.Now())
+	return t.repo.UpdateSession(sess)
+}
+
+func (t *timedSessionRepo) DeleteSession(sessionID string) (*session.Session, []error) {
+	defer t.observe("delete", time.Now())
+	return t.repo.DeleteSession(sessionID)
+}
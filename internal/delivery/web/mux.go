@@ -2,12 +2,15 @@ package web
 
 import (
 	"html/template"
-	"log"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"github.com/slim-crown/issue-1-website/internal/tracing"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -16,15 +19,26 @@ import (
 type Setup struct {
 	Config
 	Dependencies
-	templates *template.Template
+	templatesMu sync.RWMutex
+	templates   *template.Template
 }
 
 // Dependencies contains dependencies used by the handlers.
 type Dependencies struct {
-	Iss1C          *issue1.Client
-	Logger         *log.Logger
+	Iss1C *issue1.Client
+	// Logger is the logger of the web component. Handlers log with the
+	// logger carried by the request which adds the request ID, the route
+	// and, once known, the session and username to the entries.
+	Logger         *logging.Logger
 	sessionValues  sessionValues
 	SessionService session.Service
+	// Tracer traces the requests served by the routes. They aren't traced
+	// if it's nil.
+	Tracer *tracing.Tracer
+	// Metrics records the requests served by the routes and the template
+	// renders, and is served at /metrics to the requests bearing the
+	// AdminToken. Nothing is recorded if it's nil.
+	Metrics *Metrics
 }
 
 // Config contains the different settings used to set up the handlers
@@ -36,6 +50,15 @@ type Config struct {
 	SessionIdleLifetime, SessionHardLifetime                  time.Duration
 	TokenSigningSecret                                        []byte
 	HTTPS                                                     bool
+	// AdminToken authenticates the requests to the admin routes and
+	// /metrics which aren't served if it's empty.
+	AdminToken string
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header
+	// sent when HTTPS is set. It isn't sent if it's zero.
+	HSTSMaxAge time.Duration
+	// TrustedProxies are the networks of the reverse proxies whose
+	// X-Forwarded-Proto header is trusted when HTTPS is set.
+	TrustedProxies []*net.IPNet
 }
 
 // NewMux returns a fully configured issue1 website server.
@@ -44,26 +67,95 @@ func NewMux(s *Setup) *httprouter.Router {
 
 	err := s.ParseTemplates()
 	if err != nil {
-		s.Logger.Printf("error: initial template parsing failed because: %v", err)
-		//s.Logger.Fatalf("fatal: server start-up aborted.")
+		s.Logger.Error("initial template parsing failed", logging.Err(err))
+		//s.Logger.Fatal("server start-up aborted")
 	}
 	s.sessionValues.restRefreshToken = "restRefreshToken"
 	s.sessionValues.csrf = "CSRF"
 	s.sessionValues.username = "username"
 
+	handle := func(method, path string, handler http.Handler) {
+		mainRouter.Handler(method, path, strictTransport(s, handler))
+	}
 	fs := http.FileServer(http.Dir(s.AssetStoragePath))
-	mainRouter.Handler("GET", s.AssetServingRoute+"*filepath", http.StripPrefix(s.AssetServingRoute, fs))
-
-	mainRouter.HandlerFunc("GET", "/", getFront(s))
-	mainRouter.HandlerFunc("POST", "/login", postLogin(s))
-	mainRouter.HandlerFunc("POST", "/signup", postSignUp(s))
-	mainRouter.HandlerFunc("GET", "/home", getHome(s))
-	mainRouter.HandlerFunc("POST", "/home-feed-posts", postFeedPosts(s))
-	mainRouter.HandlerFunc("GET", "/error", getError(s))
-	mainRouter.HandlerFunc("GET", "/404", get404(s))
-	mainRouter.HandlerFunc("GET", "/p/:postID", getPostView(s))
-	mainRouter.HandlerFunc("POST", "/p/:postID/comment-board", postPostComments(s))
-	mainRouter.HandlerFunc("POST", "/p/:postID/add-comment", postComment(s))
+	handle("GET", s.AssetServingRoute+"*filepath", http.StripPrefix(s.AssetServingRoute, fs))
+
+	route := func(method, path string, handler http.HandlerFunc) {
+		handled := s.Metrics.handler(method, path, logRequests(s, method+" "+path, handler))
+		handle(method, path, tracing.Handler(s.Tracer, method+" "+path, handled))
+	}
+	route("GET", "/", getFront(s))
+	route("POST", "/login", postLogin(s))
+	route("POST", "/signup", postSignUp(s))
+	route("GET", "/home", getHome(s))
+	route("POST", "/home-feed-posts", postFeedPosts(s))
+	route("GET", "/error", getError(s))
+	route("GET", "/404", get404(s))
+	route("GET", "/p/:postID", getPostView(s))
+	route("POST", "/p/:postID/comment-board", postPostComments(s))
+	route("POST", "/p/:postID/add-comment", postComment(s))
+
+	if s.AdminToken != "" {
+		route("POST", "/admin/reload-templates", adminOnly(s, postReloadTemplates(s)))
+		if s.Metrics != nil {
+			handle("GET", "/metrics", adminOnly(s, s.Metrics.Registry.ServeHTTP))
+		}
+	}
 
 	return mainRouter
 }
+
+// logRequests puts a logger carrying the request ID and the route in the
+// context of the requests served by handler and logs them once served. The
+// request ID is taken from the RequestIDHeader of the request if it has a
+// valid one and passed on to the REST server.
+func logRequests(s *Setup, route string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(issue1.RequestIDHeader)
+		if !validRequestID(id) {
+			id = generateRandomID(16)
+		}
+		l := s.Logger.With(logging.F("request_id", id), logging.F("route", route))
+		if sc := tracing.SpanFromContext(r.Context()).SpanContext(); sc.IsValid() {
+			l = l.With(logging.F("trace_id", sc.TraceID.String()))
+		}
+		ctx := logging.NewContext(issue1.WithRequestID(r.Context(), id), l)
+		rec := tracing.NewResponseRecorder(w)
+		handler.ServeHTTP(rec, r.WithContext(ctx))
+		logging.FromContext(ctx).Info("request served",
+			logging.F("status", rec.Status),
+			logging.F("duration", time.Since(start)))
+	})
+}
+
+// maxRequestIDLength is the length of the longest request ID accepted from
+// the requests.
+const maxRequestIDLength = 64
+
+// validRequestID reports whether the request ID id of a request can be
+// logged and passed on to the REST server as is. It mustn't be empty or
+// longer than maxRequestIDLength and may only hold ASCII letters, digits,
+// dots, underscores and hyphens.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// logger returns the logger of the request.
+func logger(s *Setup, r *http.Request) *logging.Logger {
+	if l := logging.FromContext(r.Context()); l != nil {
+		return l
+	}
+	return s.Logger
+}
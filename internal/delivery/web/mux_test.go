@@ -0,0 +1,219 @@
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	memoryRepo "github.com/slim-crown/issue-1-website/internal/repositories/memory"
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1/issue1test"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+var loveless = issue1.User{
+	Username:  "loveless",
+	Password:  "password",
+	Email:     "stars@destination.com",
+	FirstName: "Kevin",
+}
+
+// newTestSetup returns a Setup serving the templates and assets of the
+// repository using the given client and keeping the sessions in memory.
+func newTestSetup(t *testing.T, i1 *issue1.Client) *Setup {
+	t.Helper()
+	s := &Setup{Config: Config{
+		TemplatesStoragePath: "../../../web/templates",
+		AssetStoragePath:     "../../../web/assets",
+		AssetServingRoute:    "/assets/",
+		CookieName:           "I1Session",
+		CSRFTokenLifetime:    time.Minute,
+		SessionIdleLifetime:  time.Hour,
+		SessionHardLifetime:  time.Hour,
+		TokenSigningSecret:   []byte("s3cr3t"),
+	}}
+	s.Logger = logging.New(ioutil.Discard, logging.FormatLogfmt)
+	s.Iss1C = i1
+	sessionRepo := memoryRepo.NewSessionRepo()
+	s.SessionService = session.NewService(&sessionRepo, s.Logger.Component("session"))
+	return s
+}
+
+// newTestWebsite serves the website backed by a fake REST server holding
+// the user loveless. The returned client keeps the cookies and doesn't
+// follow redirects. Both servers are closed when the test ends.
+func newTestWebsite(t *testing.T) (*issue1test.Server, *httptest.Server, *http.Client) {
+	t.Helper()
+	rest := issue1test.NewServer()
+	rest.AddUser(loveless)
+	website := httptest.NewServer(NewMux(newTestSetup(t, rest.Client())))
+	t.Cleanup(func() {
+		website.Close()
+		rest.Close()
+	})
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return rest, website, client
+}
+
+var csrfField = regexp.MustCompile(`name="_csrf" value="([^"]+)"`)
+
+// frontCSRF returns the CSRF token of the login form of the front page.
+func frontCSRF(t *testing.T, website *httptest.Server, client *http.Client) string {
+	t.Helper()
+	body := readBody(t, get(t, client, website.URL+"/"))
+	m := csrfField.FindStringSubmatch(body)
+	if m == nil {
+		t.Fatalf("front page has no CSRF token:\n%s", body)
+	}
+	return m[1]
+}
+
+// postLoginForm submits the login form of the front page.
+func postLoginForm(t *testing.T, website *httptest.Server, client *http.Client, csrf, username, password string) *http.Response {
+	t.Helper()
+	resp, err := client.PostForm(website.URL+"/login", url.Values{
+		"_csrf":    {csrf},
+		"Username": {username},
+		"Password": {password},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// login logs the client in as loveless.
+func login(t *testing.T, website *httptest.Server, client *http.Client) {
+	t.Helper()
+	resp := postLoginForm(t, website, client, frontCSRF(t, website, client), loveless.Username, loveless.Password)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/home" {
+		t.Fatalf("login: %d to %q, want a redirect to /home", resp.StatusCode, resp.Header.Get("Location"))
+	}
+}
+
+func get(t *testing.T, client *http.Client, url string) *http.Response {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestNewMuxRoutes checks that every route is served by its handler.
+func TestNewMuxRoutes(t *testing.T) {
+	_, website, client := newTestWebsite(t)
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{"/", http.StatusOK},
+		{"/home", http.StatusSeeOther},
+		{"/nowhere", http.StatusNotFound},
+	} {
+		resp := get(t, client, website.URL+tc.path)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("GET %s: status = %d, want %d", tc.path, resp.StatusCode, tc.status)
+		}
+	}
+}
+
+func TestMetricsAdminOnly(t *testing.T) {
+	rest := issue1test.NewServer()
+	defer rest.Close()
+	s := newTestSetup(t, rest.Client())
+	s.Metrics = NewMetrics()
+
+	// /metrics isn't served without an admin token
+	w := httptest.NewRecorder()
+	NewMux(s).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("without an admin token: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	s.AdminToken = "0123456789abcdef"
+	mux := NewMux(s)
+	for _, tc := range []struct {
+		authorization string
+		status        int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer fedcba9876543210", http.StatusUnauthorized},
+		{"Bearer 0123456789abcdef", http.StatusOK},
+	} {
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		if tc.authorization != "" {
+			r.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != tc.status {
+			t.Errorf("Authorization %q: status = %d, want %d", tc.authorization, w.Code, tc.status)
+		}
+	}
+}
+
+func TestLogRequestsRequestID(t *testing.T) {
+	s := newTestSetup(t, nil)
+	var got string
+	handler := logRequests(s, "GET /", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = issue1.RequestIDFromContext(r.Context())
+	}))
+
+	for _, tc := range []struct {
+		name string
+		id   string
+		kept bool
+	}{
+		{"valid", "4bf92f3577b34da6.a3ce929d_0e0e-4736", true},
+		{"longest", strings.Repeat("a", maxRequestIDLength), true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"log injection", "id\nlevel=error msg=forged", false},
+		{"spaces", "an id", false},
+		{"non-ASCII", "ïd", false},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if tc.id != "" {
+			r.Header.Set(issue1.RequestIDHeader, tc.id)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if tc.kept {
+			if got != tc.id {
+				t.Errorf("%s: request ID = %q, want %q", tc.name, got, tc.id)
+			}
+			continue
+		}
+		if got == tc.id || !validRequestID(got) {
+			t.Errorf("%s: request ID = %q, want a generated one", tc.name, got)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/slim-crown/issue-1-website/internal/services/session"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 	"net/http"
 	"strconv"
 	"time"
@@ -37,7 +39,7 @@ func postComment(s *Setup) http.HandlerFunc {
 
 		valid := validCSRF(temp.CSRF, s.TokenSigningSecret)
 		if !valid || temp.CSRF != sess.Get(s.sessionValues.csrf) {
-			s.Logger.Printf(" login attempt with incorrect CSRF token at username %s", r.FormValue("Username"))
+			logger(s, r).Warn("comment attempt with incorrect CSRF token")
 			newToken, err := cSRFToken(
 				"", //no subject for login/sign up forms
 				s.TokenSigningSecret,
@@ -50,7 +52,7 @@ func postComment(s *Setup) http.HandlerFunc {
 
 			err = sess.Set(s.sessionValues.csrf, newToken)
 			if err != nil {
-				s.Logger.Printf("server error setting value on session because: %v", err)
+				logger(s, r).Error("setting value on session failed", logging.Err(err))
 				showErrorPage(w, r)
 				return
 			}
@@ -59,7 +61,7 @@ func postComment(s *Setup) http.HandlerFunc {
 			}
 
 			w.WriteHeader(http.StatusBadRequest)
-			_ = s.templates.ExecuteTemplate(w, "comment.form", commentForm)
+			_ = s.executeTemplate(w, "comment.form", commentForm)
 			return
 		}
 		comment := issue1.Comment{
@@ -67,22 +69,19 @@ func postComment(s *Setup) http.HandlerFunc {
 			Content:   temp.Comment,
 			ReplyTo:   -1,
 		}
-		_, err = s.Iss1C.CommentService.AddComment(uint(postID), &comment, sess.Get(s.sessionValues.restRefreshToken))
+		_, err = s.Iss1C.CommentService.AddCommentContext(restContext(s, sess, r), uint(postID), &comment, sess.Get(s.sessionValues.restRefreshToken))
 		if err != nil {
-			if err == issue1.ErrAccessDenied {
-				err = refreshTokenAuthOnSession(sess, s, w, r)
-				if err != nil {
-					return
-				}
-				_, err = s.Iss1C.CommentService.AddComment(uint(postID), &comment, sess.Get(s.sessionValues.restRefreshToken))
-				if err != nil {
-					showErrorPage(w, r)
-					return
-				}
-			} else {
-				showErrorPage(w, r)
+			if errors.Is(err, issue1.ErrAccessDenied) {
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+				return
+			}
+			var vErrs issue1.ValidationErrors
+			if errors.As(err, &vErrs) {
+				http.Error(w, vErrs.Get("content"), http.StatusBadRequest)
 				return
 			}
+			showRESTErrorPage(s, w, r, err)
+			return
 		}
 		w.WriteHeader(http.StatusOK)
 	}
@@ -109,9 +108,9 @@ func postPostComments(s *Setup) http.HandlerFunc {
 			return
 		}
 
-		comments, err := s.Iss1C.CommentService.GetCommentsPaged(p.Page, p.PerPage, uint(postID))
+		comments, err := s.Iss1C.CommentService.GetCommentsPagedContext(r.Context(), p.Page, p.PerPage, uint(postID))
 		if err != nil {
-			showErrorPage(w, r)
+			showRESTErrorPage(s, w, r, err)
 			return
 		}
 		type augmentedComment struct {
@@ -119,17 +118,14 @@ func postPostComments(s *Setup) http.HandlerFunc {
 			Commenter *issue1.User
 			Replies   []*augmentedComment
 		}
+		commenters := getCommenters(s, r, comments)
 		augComments := make([]*augmentedComment, 0)
 		replies := make(map[int][]*augmentedComment, 0)
 		for _, comment := range comments {
 			augComment := augmentedComment{
-				Comment: comment,
-				Replies: make([]*augmentedComment, 0),
-			}
-			augComment.Commenter, err = s.Iss1C.UserService.GetUser(comment.Commenter)
-			if err != nil {
-				showErrorPage(w, r)
-				return
+				Comment:   comment,
+				Commenter: commenters[comment.Commenter],
+				Replies:   make([]*augmentedComment, 0),
 			}
 			replies[int(comment.ID)] = augComment.Replies
 			augComments = append(augComments, &augComment)
@@ -142,7 +138,7 @@ func postPostComments(s *Setup) http.HandlerFunc {
 				replies[augComment.ReplyTo] = append(replies[augComment.ReplyTo], augComment)
 			}
 		}
-		_ = s.templates.ExecuteTemplate(w, "comment.board", boardData)
+		_ = s.executeTemplate(w, "comment.board", boardData)
 	}
 }
 
@@ -176,7 +172,7 @@ func getPostView(s *Setup) http.HandlerFunc {
 		}
 		err = sess.Set(s.sessionValues.csrf, postData.CSRF)
 		if err != nil {
-			s.Logger.Printf("server error setting value on session because: %v", err)
+			logger(s, r).Error("setting value on session failed", logging.Err(err))
 			showErrorPage(w, r)
 			return
 		}
@@ -184,26 +180,57 @@ func getPostView(s *Setup) http.HandlerFunc {
 		if err != nil {
 			return
 		}
-		postData.Post, err = s.Iss1C.PostService.GetPost(uint(postID))
+		postData.Post, err = s.Iss1C.PostService.GetPostContext(r.Context(), uint(postID))
 		if err != nil {
-			if err == issue1.ErrPostNotFound {
+			if errors.Is(err, issue1.ErrPostNotFound) {
 				show404Page(w, r)
 				return
 			}
-			showErrorPage(w, r)
+			showRESTErrorPage(s, w, r, err)
 			return
 		}
-		postData.Releases = make([]*issue1.Release, 0)
-		for _, id := range postData.Post.ContentsID {
-			rel, err := s.Iss1C.ReleaseService.GetRelease(id)
-			if err != nil {
-				showErrorPage(w, r)
-				return
-			}
-			postData.Releases = append(postData.Releases, rel)
+		postData.Releases = getReleases(s, r, postData.Post.ContentsID)
+		_ = s.executeTemplate(w, "post.view", postData)
+	}
+}
+
+// getReleases fetches the releases under the given ids. Releases that couldn't
+// be fetched are left out so that a single missing one doesn't blank the
+// whole post.
+func getReleases(s *Setup, r *http.Request, ids []uint) []*issue1.Release {
+	fetched, errs := s.Iss1C.ReleaseService.GetReleasesBatchContext(r.Context(), ids)
+	releases := make([]*issue1.Release, 0, len(ids))
+	for i, rel := range fetched {
+		if errs[i] != nil {
+			logger(s, r).Warn("fetching release failed", logging.F("release_id", ids[i]), logging.Err(errs[i]))
+			continue
 		}
-		_ = s.templates.ExecuteTemplate(w, "post.view", postData)
+		releases = append(releases, rel)
 	}
+	return releases
+}
+
+// getCommenters fetches the users that made the given comments mapped by
+// their username. Users that couldn't be fetched are replaced by ones
+// holding only the username.
+func getCommenters(s *Setup, r *http.Request, comments []*issue1.Comment) map[string]*issue1.User {
+	usernames := make([]string, 0, len(comments))
+	commenters := make(map[string]*issue1.User, len(comments))
+	for _, comment := range comments {
+		if _, ok := commenters[comment.Commenter]; !ok {
+			commenters[comment.Commenter] = &issue1.User{Username: comment.Commenter}
+			usernames = append(usernames, comment.Commenter)
+		}
+	}
+	users, errs := s.Iss1C.UserService.GetUsersBatchContext(r.Context(), usernames)
+	for i, user := range users {
+		if errs[i] != nil {
+			logger(s, r).Warn("fetching commenter failed", logging.F("commenter", usernames[i]), logging.Err(errs[i]))
+			continue
+		}
+		commenters[usernames[i]] = user
+	}
+	return commenters
 }
 
 type NavBarData struct {
@@ -216,23 +243,14 @@ func getNavbarData(s *Setup, sess *session.Session, w http.ResponseWriter, r *ht
 	username := sess.Get(s.sessionValues.username)
 	authToken := sess.Get(s.sessionValues.restRefreshToken)
 	navData.Username = username
-	subs, err := s.Iss1C.FeedService.GetFeedSubscriptions(username, authToken, issue1.SortBySubscriptionTime, issue1.SortDescending)
+	subs, err := s.Iss1C.FeedService.GetFeedSubscriptionsContext(restContext(s, sess, r), username, authToken, issue1.SortBySubscriptionTime, issue1.SortDescending)
 	if err != nil {
-		if err == issue1.ErrAccessDenied {
-			err = refreshTokenAuthOnSession(sess, s, w, r)
-			if err != nil {
-				return nil, err
-			}
-			subs, err = s.Iss1C.FeedService.GetFeedSubscriptions(username, sess.Get(s.sessionValues.restRefreshToken),
-				issue1.SortBySubscriptionTime, issue1.SortDescending)
-			if err != nil {
-				showErrorPage(w, r)
-				return nil, err
-			}
-		} else {
-			showErrorPage(w, r)
-			return nil, err
+		if errors.Is(err, issue1.ErrAccessDenied) {
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return nil, errRefreshTokenExpired
 		}
+		showRESTErrorPage(s, w, r, err)
+		return nil, err
 	}
 	navData.Subs = subs
 	return &navData, nil
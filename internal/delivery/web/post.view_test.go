@@ -0,0 +1,85 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// TestPostPostCommentsReplay replays a session recorded with the
+// record_cassette setting in which a commenter of the post couldn't be
+// fetched. The board is still rendered with the comments of every
+// commenter.
+func TestPostPostCommentsReplay(t *testing.T) {
+	cassette, err := issue1.LoadCassette("testdata/comment-board.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseURL, _ := url.Parse("http://rest.invalid")
+	i1 := issue1.NewClient(http.DefaultClient, baseURL, nil)
+	i1.Use(cassette.Replay())
+	mux := NewMux(newTestSetup(t, i1))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/p/1/comment-board", strings.NewReader(`{"page":1,"perPage":10}`)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	for _, want := range []string{
+		"soft as a cloud", ">loveless<", "/images/loveless.jpg",
+		// the commenter that couldn't be fetched
+		"to here knows when", ">bilinda<",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("comment board doesn't contain %q:\n%s", want, w.Body.String())
+		}
+	}
+}
+
+func TestGetPostView(t *testing.T) {
+	rest, website, client := newTestWebsite(t)
+	rest.AddChannel(issue1.Channel{ChannelUsername: "chromagnum", Name: "Chromagnum", OwnerUsername: loveless.Username})
+	release := rest.AddRelease(issue1.Release{
+		OwnerChannel: "chromagnum",
+		Type:         "text",
+		Content:      "when you wake, you're still in a dream",
+		Metadata:     issue1.Metadata{Title: "Loveless Liner Notes", GenreDefining: "Shoegaze"},
+	})
+	post := rest.AddPost(issue1.Post{
+		Title:            "Only Shallow",
+		PostedByUsername: loveless.Username,
+		OriginChannel:    "chromagnum",
+		ContentsID:       []uint{release.ID},
+	})
+	postURL := fmt.Sprintf("%s/p/%d", website.URL, post.ID)
+
+	// the post view is only shown to logged in users
+	resp := get(t, client, postURL)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/" {
+		t.Errorf("logged out: %d to %q, want a redirect to /", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	login(t, website, client)
+	resp = get(t, client, postURL)
+	body := readBody(t, resp)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d:\n%s", resp.StatusCode, http.StatusOK, body)
+	}
+	for _, want := range []string{"Only Shallow", "Loveless Liner Notes", "still in a dream"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("post view doesn't contain %q", want)
+		}
+	}
+
+	for _, path := range []string{"/p/404", "/p/shallow", "/p/0"} {
+		if resp := get(t, client, website.URL+path); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want %d", path, resp.StatusCode, http.StatusNotFound)
+		}
+	}
+}
@@ -2,21 +2,19 @@ package web
 
 import (
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 	"net/http"
 	"time"
 )
 
 func getAccountView(s *Setup) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		s.Logger.Printf("fkdls")
 		sess, err := SessionStartLoggedIn(s, w, r)
 		if err != nil {
-			s.Logger.Printf("%s", err.Error())
 			return
 		}
 
 		username := sess.Get(s.sessionValues.username)
-		authToken := sess.Get(s.sessionValues.restRefreshToken)
 		var UserAccountData struct {
 			User            *issue1.User
 			BookmarkedPosts map[time.Time]*issue1.Post
@@ -24,22 +22,22 @@ func getAccountView(s *Setup) http.HandlerFunc {
 		}
 		UserAccountData.NavBarData, err = getNavbarData(s, sess, w, r)
 		if err != nil {
-			s.Logger.Printf("%s", err.Error())
+			logger(s, r).Warn("fetching navbar data failed", logging.Err(err))
 			return
 		}
-		UserAccountData.User, err = s.Iss1C.UserService.GetUser(username)
+		UserAccountData.User, err = s.Iss1C.UserService.GetUserContext(r.Context(), username)
 		if err != nil {
-			s.Logger.Printf("%s", err.Error())
+			logger(s, r).Warn("fetching user failed", logging.Err(err))
 			return
 		}
 
-		UserAccountData.BookmarkedPosts, err = s.Iss1C.UserService.GetUserBookmarks(username, authToken)
+		UserAccountData.BookmarkedPosts, err = s.Iss1C.UserService.GetUserBookmarksContext(restContext(s, sess, r), username, sess.Get(s.sessionValues.restRefreshToken))
 		if err != nil {
-			s.Logger.Printf("%s", err.Error())
+			logger(s, r).Warn("fetching bookmarks failed", logging.Err(err))
 			return
 		}
 
-		_ = s.templates.ExecuteTemplate(w, "account", UserAccountData)
+		_ = s.executeTemplate(w, "account", UserAccountData)
 
 	}
 }
@@ -1,16 +1,18 @@
 package web
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/jinzhu/gorm"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"github.com/slim-crown/issue-1-website/internal/tracing"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 )
 
 type sessionValues struct {
@@ -26,28 +28,35 @@ type SessionTokenClaims struct {
 	RestAccessToken string `json:"token,omitempty"`
 }
 
+var errInsecureRequest = errors.New("session: sessions aren't started over plain HTTP when HTTPS is set")
+
 // sessionStart looks for a sessionID on the request cookies and returns the
 // session under it if found. If not found, it creates a new session and attaches
-// a new cookie.
+// a new cookie. Sessions aren't started for plain HTTP requests if HTTPS is set
+// so that their cookies never travel in the clear, such requests being
+// redirected to HTTPS before reaching the handlers.
 func sessionStart(s *Setup, w http.ResponseWriter, r *http.Request) (*session.Session, error) {
+	if s.HTTPS && !secureRequest(s, r) {
+		return nil, errInsecureRequest
+	}
 	cookie, err := r.Cookie(s.CookieName)
 	if err == nil && cookie.Value != "" {
 		// if session found on cookie
 		sid, _ := url.QueryUnescape(cookie.Value)
 		sess, errs := s.SessionService.GetSession(sid)
-		// TODO session not found
 		sessionFound := true
 		for _, err := range errs {
-			if gorm.IsRecordNotFoundError(err) {
+			if errors.Is(err, session.ErrSessionNotFound) {
 				sessionFound = false
 			} else {
 				return nil, fmt.Errorf("unable to retrieve session because: %+v", errs)
 			}
 		}
 		if sessionFound {
-			// TODO check if max age gets updated
-			//cookie.MaxAge = int(s.SessionHardLifetime.Seconds())
-			w.Header().Set("Set-Cookie", cookie.String())
+			addSessionLogFields(s, r, sess)
+			// the cookie is resent with its attributes which the
+			// browser doesn't send back
+			w.Header().Set("Set-Cookie", sessionCookie(s, sess).String())
 			return sess, nil
 		}
 	}
@@ -57,19 +66,39 @@ func sessionStart(s *Setup, w http.ResponseWriter, r *http.Request) (*session.Se
 		return nil, fmt.Errorf("unable to create session because: %+v", errs)
 	}
 
-	cookie = &http.Cookie{
+	w.Header().Set("Set-Cookie", sessionCookie(s, sess).String())
+	addSessionLogFields(s, r, sess)
+
+	//fmt.Printf("Session: %+v\nCookie:%+v\n", sess, cookie)
+	return sess, nil
+}
+
+// sessionCookie returns the cookie carrying the ID of the session. It's
+// only sent over HTTPS if the website is served over it.
+func sessionCookie(s *Setup, sess *session.Session) *http.Cookie {
+	maxAge := int(time.Until(sess.Expires).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+	return &http.Cookie{
 		Name:     s.CookieName,
 		Value:    sess.UUID,
-		MaxAge:   int(s.SessionHardLifetime.Seconds()),
+		Path:     "/",
+		MaxAge:   maxAge,
 		SameSite: http.SameSiteStrictMode,
 		Secure:   s.HTTPS,
 		HttpOnly: true,
 	}
+}
 
-	w.Header().Set("Set-Cookie", cookie.String())
-
-	//fmt.Printf("Session: %+v\nCookie:%+v\n", sess, cookie)
-	return sess, nil
+// addSessionLogFields adds the hash of the session ID and the username of
+// the session, if it's logged in, to the logger of the request.
+func addSessionLogFields(s *Setup, r *http.Request, sess *session.Session) {
+	fields := []logging.Field{logging.F("session", session.HashID(sess.UUID))}
+	if username := sess.Get(s.sessionValues.username); username != "" {
+		fields = append(fields, logging.F("username", username))
+	}
+	logging.AddFields(r.Context(), fields...)
 }
 
 var errNotLoggedIn = errors.New("session: session found not logged in")
@@ -82,37 +111,40 @@ var errRefreshTokenExpired = errors.New("session: refresh token found on session
 func SessionStartLoggedIn(s *Setup, w http.ResponseWriter, r *http.Request) (*session.Session, error) {
 	sess, err := sessionStart(s, w, r)
 	if err != nil {
-		s.Logger.Printf("server error starting session because: %v", err)
+		logger(s, r).Error("starting session failed", logging.Err(err))
 		showErrorPage(w, r)
 		return nil, err
 	}
-	if sess.Get(s.sessionValues.username) == "" {
+	username := sess.Get(s.sessionValues.username)
+	if username == "" {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return nil, errNotLoggedIn
 	}
+	tracing.SpanFromContext(r.Context()).SetAttribute("username", username)
 	return sess, nil
 }
 
-func refreshTokenAuthOnSession(sess *session.Session, s *Setup, w http.ResponseWriter, r *http.Request) error {
-	authToken := sess.Get(s.sessionValues.restRefreshToken)
-	authToken, err := s.Iss1C.RefreshAuthToken(authToken)
-	switch err {
-	case nil:
-		err = sess.Set(s.sessionValues.restRefreshToken, authToken)
-		if err != nil {
-			s.Logger.Printf("server error setting auth token on session because: %v", err)
-			showErrorPage(w, r)
-			return err
-		}
-		return nil
-	case issue1.ErrAccessDenied:
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return errRefreshTokenExpired
-	default:
-		s.Logger.Printf("server error refreshing token refreshing token because: %v", err)
-		showErrorPage(w, r)
-		return err
-	}
+// sessionTokenSource is an issue1.TokenSource that keeps the REST auth
+// token on the session.
+type sessionTokenSource struct {
+	s    *Setup
+	sess *session.Session
+}
+
+func (ts sessionTokenSource) Token() string {
+	return ts.sess.Get(ts.s.sessionValues.restRefreshToken)
+}
+
+func (ts sessionTokenSource) SetToken(token string) error {
+	return ts.sess.Set(ts.s.sessionValues.restRefreshToken, token)
+}
+
+// restContext returns the context of the request carrying the session's
+// auth token so that the REST client can refresh it if it's expired. Calls
+// made with it only fail with issue1.ErrAccessDenied if the token couldn't
+// be refreshed, meaning the user must log in again.
+func restContext(s *Setup, sess *session.Session, r *http.Request) context.Context {
+	return issue1.WithTokenSource(r.Context(), sessionTokenSource{s, sess})
 }
 
 // sessionDestroy removes all cookies set by session start.
@@ -130,6 +162,7 @@ func sessionDestroy(s *Setup, w http.ResponseWriter, r *http.Request) error {
 		Name:     s.CookieName,
 		Path:     "/",
 		HttpOnly: true,
+		Secure:   s.HTTPS,
 		Expires:  time.Now(),
 		MaxAge:   -1,
 	}
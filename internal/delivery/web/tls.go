@@ -0,0 +1,85 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// strictTransport has browsers only use HTTPS from then on by sending the
+// Strict-Transport-Security header when HTTPS is set. Requests that didn't
+// come over HTTPS are redirected to it.
+func strictTransport(s *Setup, handler http.Handler) http.Handler {
+	if !s.HTTPS {
+		return handler
+	}
+	value := ""
+	if s.HSTSMaxAge > 0 {
+		value = "max-age=" + strconv.Itoa(int(s.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !secureRequest(s, r) {
+			redirectToHTTPS(s, w, r)
+			return
+		}
+		if value != "" {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// RedirectToHTTPS returns a handler permanently redirecting the requests it
+// receives to the same URL served over HTTPS on the port of the Setup.
+func RedirectToHTTPS(s *Setup) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirectToHTTPS(s, w, r)
+	})
+}
+
+func redirectToHTTPS(s *Setup, w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	// the proxies serve HTTPS on its default port
+	if s.Port != "443" && !fromTrustedProxy(s, r) {
+		host = net.JoinHostPort(host, s.Port)
+	}
+	target := "https://" + host + r.URL.RequestURI()
+	// clients may turn other methods into GETs when following a 301
+	status := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+	http.Redirect(w, r, target, status)
+}
+
+// secureRequest reports whether the request came over HTTPS, either
+// directly or through one of the trusted proxies.
+func secureRequest(s *Setup, r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return fromTrustedProxy(s, r) && strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// fromTrustedProxy reports whether the request was sent by one of the
+// TrustedProxies.
+func fromTrustedProxy(s *Setup, r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range s.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
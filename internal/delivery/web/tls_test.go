@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStrictTransport(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	s := &Setup{Config: Config{
+		Port:           "8443",
+		HTTPS:          true,
+		HSTSMaxAge:     time.Hour,
+		TrustedProxies: []*net.IPNet{proxies},
+	}}
+	handler := strictTransport(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("served"))
+	}))
+
+	for _, tc := range []struct {
+		name       string
+		target     string
+		remoteAddr string
+		proto      string
+		location   string
+	}{
+		{"over TLS", "https://issue1.example/p/1", "192.0.2.1:4321", "", ""},
+		{"plain", "http://issue1.example/p/1", "192.0.2.1:4321", "", "https://issue1.example:8443/p/1"},
+		{"untrusted proxy", "http://issue1.example/p/1", "192.0.2.1:4321", "https", "https://issue1.example:8443/p/1"},
+		{"trusted proxy over HTTPS", "http://issue1.example/p/1", "10.1.2.3:4321", "https", ""},
+		{"trusted proxy over HTTP", "http://issue1.example/p/1?page=2", "10.1.2.3:4321", "http", "https://issue1.example/p/1?page=2"},
+	} {
+		r := httptest.NewRequest("GET", tc.target, nil)
+		r.RemoteAddr = tc.remoteAddr
+		if tc.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tc.proto)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if tc.location != "" {
+			if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != tc.location {
+				t.Errorf("%s: %d to %q, want a redirect to %q", tc.name, w.Code, w.Header().Get("Location"), tc.location)
+			}
+			continue
+		}
+		if w.Code != http.StatusOK || w.Body.String() != "served" {
+			t.Errorf("%s: %d %q, want the request served", tc.name, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=3600; includeSubDomains" {
+			t.Errorf("%s: Strict-Transport-Security = %q", tc.name, got)
+		}
+	}
+}
@@ -97,3 +97,24 @@ func (ve ValidationErrors) Get(field string) string {
 	}
 	return ves[0]
 }
+
+// restFormFields maps the field names the REST server uses on its failure
+// reasons to the names of the inputs used on the website's forms.
+var restFormFields = map[string]string{
+	"username":   "Username",
+	"email":      "Email",
+	"password":   "Password",
+	"firstName":  "FirstName",
+	"middleName": "MiddleName",
+	"lastName":   "LastName",
+}
+
+// formFieldOf returns the name of the form input the given REST field
+// corresponds to. Unknown fields map to "generic" so their messages still
+// get displayed.
+func formFieldOf(restField string) string {
+	if field, ok := restFormFields[restField]; ok {
+		return field
+	}
+	return "generic"
+}
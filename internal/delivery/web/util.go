@@ -3,14 +3,20 @@ package web
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 
 	mrand "math/rand"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 )
 
 func getParametersFromRequestAsMap(r *http.Request) map[string]string {
@@ -81,6 +87,48 @@ func showErrorPage(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
 
+// showDegradedPage tells the user the website can't reach the REST server
+// without having them wait for requests that are bound to fail.
+func showDegradedPage(s *Setup, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", "30")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	err := s.executeTemplate(w, "degraded.layout", nil)
+	if err != nil {
+		logger(s, r).Error("template execution failed", logging.Err(err))
+		_, _ = fmt.Fprintln(w, http.StatusText(http.StatusServiceUnavailable))
+	}
+}
+
+// showRateLimitedPage tells the user they're browsing faster than the REST
+// client's rate limits allow and when they can try again.
+func showRateLimitedPage(s *Setup, w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+	err := s.executeTemplate(w, "ratelimited.layout", nil)
+	if err != nil {
+		logger(s, r).Error("template execution failed", logging.Err(err))
+		_, _ = fmt.Fprintln(w, http.StatusText(http.StatusTooManyRequests))
+	}
+}
+
+// showRESTErrorPage shows the page fit for an unexpected error returned by
+// the REST client.
+func showRESTErrorPage(s *Setup, w http.ResponseWriter, r *http.Request, err error) {
+	var rlErr *issue1.RateLimitError
+	switch {
+	case errors.As(err, &rlErr):
+		showRateLimitedPage(s, w, r, rlErr.RetryAfter)
+	case errors.Is(err, issue1.ErrCircuitOpen):
+		showDegradedPage(s, w, r)
+	default:
+		showErrorPage(w, r)
+	}
+}
+
 func show404Page(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 }
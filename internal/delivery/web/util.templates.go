@@ -1,13 +1,21 @@
 package web
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 	"html/template"
-	"log"
+	"io"
+	"io/ioutil"
 	"strings"
+	"time"
 )
 
-// ParseTemplates is used to refresh the templates from disk.
+// ParseTemplates is used to refresh the templates from disk. It's safe to
+// call while requests are being served. The templates in use are kept if
+// parsing fails.
 func (s *Setup) ParseTemplates() error {
 	funcMap := template.FuncMap{
 		"postStarCount":      postStarCount(),
@@ -20,10 +28,74 @@ func (s *Setup) ParseTemplates() error {
 	if err != nil {
 		return err
 	}
-	log.Printf("%s\n", temp.DefinedTemplates())
+	s.Logger.Debug("templates parsed", logging.F("templates", temp.DefinedTemplates()))
+	s.templatesMu.Lock()
 	s.templates = temp
+	s.templatesMu.Unlock()
 	return nil
 }
+
+var errTemplatesNotParsed = errors.New("web: templates aren't parsed")
+
+// executeTemplate renders the named template to w, recording the time it
+// took.
+func (s *Setup) executeTemplate(w io.Writer, name string, data interface{}) error {
+	s.templatesMu.RLock()
+	templates := s.templates
+	s.templatesMu.RUnlock()
+	if templates == nil {
+		return errTemplatesNotParsed
+	}
+	start := time.Now()
+	err := templates.ExecuteTemplate(w, name, data)
+	s.Metrics.observeRender(name, time.Since(start))
+	return err
+}
+
+// WatchTemplates reparses the templates whenever a file of the
+// TemplatesStoragePath is added, removed or modified until ctx is done. The
+// directory is polled at the given interval. It's meant for development.
+func (s *Setup) WatchTemplates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last, _ := templatesVersion(s.TemplatesStoragePath)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		version, err := templatesVersion(s.TemplatesStoragePath)
+		if err != nil {
+			s.Logger.Warn("watching templates failed", logging.Err(err))
+			continue
+		}
+		if version == last {
+			continue
+		}
+		last = version
+		if err := s.ParseTemplates(); err != nil {
+			s.Logger.Error("template parsing failed, the previous templates are kept", logging.Err(err))
+			continue
+		}
+		s.Logger.Info("templates reloaded")
+	}
+}
+
+// templatesVersion returns a string that changes whenever a file of the
+// directory is added, removed or modified.
+func templatesVersion(dir string) (string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&b, "%s %d %d\n", f.Name(), f.ModTime().UnixNano(), f.Size())
+	}
+	return b.String(), nil
+}
+
 func PreviewTextRelease(r *issue1.Release) (out string) {
 	ctr := 0
 	for _, line := range strings.Split(r.Content, "\n") {
@@ -0,0 +1,96 @@
+// Package devcert generates self-signed TLS certificates so that the website
+// can be served over HTTPS during development.
+package devcert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lifetime is how long the generated certificates are valid for.
+const Lifetime = 365 * 24 * time.Hour
+
+// Ensure generates a self-signed certificate for the given hosts, along
+// with localhost, and writes it and its key to the given files unless both
+// exist already. It reports whether it generated one.
+func Ensure(certFile, keyFile string, hosts []string) (bool, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	if certErr == nil && keyErr == nil {
+		return false, nil
+	}
+	cert, key, err := Generate(hosts)
+	if err != nil {
+		return false, err
+	}
+	if err := write(certFile, cert, 0644); err != nil {
+		return false, err
+	}
+	if err := write(keyFile, key, 0600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Generate returns a PEM encoded self-signed certificate for the given
+// hosts, along with localhost, and its PEM encoded key. Hosts can be names
+// or IP addresses.
+func Generate(hosts []string) (cert, key []byte, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("devcert: unable to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("devcert: unable to generate serial number: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"issue#1 development"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(Lifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range append([]string{"localhost", "127.0.0.1", "::1"}, hosts...) {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("devcert: unable to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("devcert: unable to marshal key: %w", err)
+	}
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return cert, key, nil
+}
+
+func write(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("devcert: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("devcert: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package devcert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsure(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls", "cert.pem"), filepath.Join(dir, "tls", "key.pem")
+
+	created, err := Ensure(certFile, keyFile, []string{"issue1.test", "10.0.0.7"})
+	if err != nil || !created {
+		t.Fatalf("Ensure() = %v, %v, want a certificate created", created, err)
+	}
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"localhost", "issue1.test", "127.0.0.1", "10.0.0.7"} {
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Errorf("certificate isn't valid for %s: %v", host, err)
+		}
+	}
+
+	created, err = Ensure(certFile, keyFile, nil)
+	if err != nil || created {
+		t.Errorf("Ensure() = %v, %v on existing files, want them kept", created, err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package metrics keeps counters, gauges and histograms and exposes them in
+// the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of
+// histograms tracking latencies.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds metrics and serves them.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	write(w *bufio.Writer, name string)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// register adds the metric. It panics if the name is taken as it can only
+// be a programming error.
+func (r *Registry) register(name, help, typ string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		panic("metrics: " + name + " is already registered")
+	}
+	r.metrics[name] = described{help: help, typ: typ, metric: m}
+}
+
+type described struct {
+	help, typ string
+	metric
+}
+
+func (d described) write(w *bufio.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, d.typ)
+	d.metric.write(w, name)
+}
+
+// ServeHTTP writes all the metrics in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	for i, m := range metrics {
+		m.write(bw, names[i])
+	}
+	_ = bw.Flush()
+}
+
+// labelSet keeps the values of a metric per combination of label values.
+type labelSet struct {
+	names  []string
+	mu     sync.Mutex
+	values map[string][]string
+}
+
+// key returns the key of the given label values, panicking if there are
+// more or less than labels.
+func (ls *labelSet) key(values []string) string {
+	if len(values) != len(ls.names) {
+		panic(fmt.Sprintf("metrics: %d label values given for %d labels", len(values), len(ls.names)))
+	}
+	key := strings.Join(values, "\xff")
+	if ls.values == nil {
+		ls.values = make(map[string][]string)
+	}
+	if _, ok := ls.values[key]; !ok {
+		ls.values[key] = append([]string(nil), values...)
+	}
+	return key
+}
+
+// sortedKeys returns the keys of the label values in a stable order.
+func (ls *labelSet) sortedKeys() []string {
+	keys := make([]string, 0, len(ls.values))
+	for key := range ls.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelValueEscaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, `"`, `\"`)
+
+// labels formats the label pairs of key along with the extra pair if any.
+func (ls *labelSet) labels(key string, extra ...string) string {
+	var pairs []string
+	for i, value := range ls.values[key] {
+		pairs = append(pairs, ls.names[i]+`="`+labelValueEscaper.Replace(value)+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+extra[1]+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	labelSet
+	counts map[string]float64
+}
+
+// NewCounterVec registers a counter partitioned by the given labels.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{labelSet: labelSet{names: labels}, counts: make(map[string]float64)}
+	r.register(name, help, "counter", c)
+	return c
+}
+
+// Inc increments the counter of the given label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which mustn't be negative, to the counter of the given label
+// values.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[c.key(labelValues)] += v
+}
+
+func (c *CounterVec) write(w *bufio.Writer, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", name, c.labels(key), formatFloat(c.counts[key]))
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	labelSet
+	buckets    []float64
+	histograms map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram partitioned by the given labels
+// with buckets of the given upper bounds, DefaultBuckets if nil.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{labelSet: labelSet{names: labels}, buckets: buckets, histograms: make(map[string]*histogram)}
+	r.register(name, help, "histogram", h)
+	return h
+}
+
+// Observe adds v to the histogram of the given label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := h.key(labelValues)
+	hist, ok := h.histograms[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.histograms[key] = hist
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			hist.counts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range h.sortedKeys() {
+		hist := h.histograms[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, h.labels(key, "le", formatFloat(bound)), hist.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, h.labels(key, "le", "+Inf"), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, h.labels(key), formatFloat(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, h.labels(key), hist.count)
+	}
+}
+
+// gaugeFunc is a gauge whose value is computed when it's served.
+type gaugeFunc func() float64
+
+// NewGaugeFunc registers a gauge whose value is returned by fn.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, "gauge", gaugeFunc(fn))
+}
+
+func (g gaugeFunc) write(w *bufio.Writer, name string) {
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(g()))
+}
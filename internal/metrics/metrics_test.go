@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	duration := r.NewHistogramVec("duration_seconds", "Time taken.", []float64{1, .5}, "route")
+	r.NewGaugeFunc("sessions", "Active sessions.", func() float64 { return 3 })
+
+	requests.Inc("/p/:postID", "200")
+	requests.Add(2, "/", "200")
+	requests.Inc(`/"quoted"`, "500")
+	duration.Observe(.25, "/")
+	duration.Observe(.75, "/")
+	duration.Observe(2, "/")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	want := `# HELP duration_seconds Time taken.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/",le="0.5"} 1
+duration_seconds_bucket{route="/",le="1"} 2
+duration_seconds_bucket{route="/",le="+Inf"} 3
+duration_seconds_sum{route="/"} 3
+duration_seconds_count{route="/"} 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/\"quoted\"",status="500"} 1
+requests_total{route="/p/:postID",status="200"} 1
+requests_total{route="/",status="200"} 2
+# HELP sessions Active sessions.
+# TYPE sessions gauge
+sessions 3
+`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("served:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistryPanicsOnDuplicates(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("requests_total", "Requests served.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering requests_total twice didn't panic")
+		}
+	}()
+	r.NewGaugeFunc("requests_total", "Requests served.", func() float64 { return 0 })
+}
+
+func TestLabelCountMismatch(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests served.", "route")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with two label values for one label didn't panic")
+		}
+	}()
+	requests.Inc("/", "200")
+}
@@ -0,0 +1,167 @@
+package bolt
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"go.etcd.io/bbolt"
+)
+
+// sessionsBucket holds the sessions encoded as JSON under their UUID.
+var sessionsBucket = []byte("sessions")
+
+// sessionRepo implements session.Repository storing the sessions in a
+// BoltDB file.
+type sessionRepo struct {
+	db *bbolt.DB
+}
+
+// NewSessionRepo returns a new session.Repository storing the sessions in
+// db. The bucket of the sessions is created if it doesn't exist.
+func NewSessionRepo(db *bbolt.DB) (session.Repository, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(sessionsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sessionRepo{db: db}, nil
+}
+
+// get returns the session stored under sessionID.
+func get(b *bbolt.Bucket, sessionID string) (*session.Session, error) {
+	return decode(b.Get([]byte(sessionID)))
+}
+
+// decode decodes a stored session. session.ErrSessionNotFound is returned
+// if there's none or it has expired.
+func decode(v []byte) (*session.Session, error) {
+	if v == nil {
+		return nil, session.ErrSessionNotFound
+	}
+	s := &session.Session{}
+	if err := json.Unmarshal(v, s); err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(s.Expires) {
+		return nil, session.ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// GetSession returns a given stored session
+func (repo *sessionRepo) GetSession(sessionID string) (*session.Session, []error) {
+	var s *session.Session
+	err := repo.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		s, err = get(tx.Bucket(sessionsBucket), sessionID)
+		return err
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return s, nil
+}
+
+// AddSession stores a given session
+func (repo *sessionRepo) AddSession(s *session.Session) (*session.Session, []error) {
+	v, err := json.Marshal(s)
+	if err != nil {
+		return nil, []error{err}
+	}
+	err = repo.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(s.UUID), v)
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return s, nil
+}
+
+// UpdateSession stores a given session. session.ErrSessionNotFound is
+// returned if it isn't stored or has expired.
+func (repo *sessionRepo) UpdateSession(s *session.Session) (*session.Session, []error) {
+	v, err := json.Marshal(s)
+	if err != nil {
+		return nil, []error{err}
+	}
+	err = repo.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		if _, err := get(b, s.UUID); err != nil {
+			return err
+		}
+		return b.Put([]byte(s.UUID), v)
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return s, nil
+}
+
+// DeleteSession deletes a given session
+func (repo *sessionRepo) DeleteSession(sessionID string) (*session.Session, []error) {
+	var s *session.Session
+	err := repo.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		var err error
+		if s, err = get(b, sessionID); err != nil {
+			return err
+		}
+		return b.Delete([]byte(sessionID))
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return s, nil
+}
+
+// StartSessionGC launches an infinite recursive routine that cleans
+// expired and undecodable sessions every interval of the specified duration.
+func (repo *sessionRepo) StartSessionGC(duration time.Duration) {
+	time.AfterFunc(duration, func() {
+		_ = repo.deleteExpired()
+		repo.StartSessionGC(duration)
+	})
+}
+
+func (repo *sessionRepo) deleteExpired() error {
+	return repo.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		var expired [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			// the sessions that can't be decoded are of no use either
+			if _, err := decode(v); err != nil {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CountSessions returns the number of sessions that haven't expired.
+func (repo *sessionRepo) CountSessions() (int, []error) {
+	count := 0
+	err := repo.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(k, v []byte) error {
+			if _, err := decode(v); err == nil {
+				count++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, []error{err}
+	}
+	return count, nil
+}
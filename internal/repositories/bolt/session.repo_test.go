@@ -0,0 +1,64 @@
+package bolt
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"github.com/slim-crown/issue-1-website/internal/services/session/sessiontest"
+	"go.etcd.io/bbolt"
+)
+
+func openDB(t *testing.T) *bbolt.DB {
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "sessions.db"), 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSessionRepo(t *testing.T) {
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		repo, err := NewSessionRepo(openDB(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+func TestDeleteExpired(t *testing.T) {
+	repo, err := NewSessionRepo(openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.AddSession(&session.Session{UUID: "expired", Expires: time.Now().Add(-time.Minute)})
+	repo.AddSession(&session.Session{UUID: "live", Expires: time.Now().Add(time.Hour)})
+	err = repo.(*sessionRepo).db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte("corrupt"), []byte("{"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.(*sessionRepo).deleteExpired(); err != nil {
+		t.Fatal(err)
+	}
+	err = repo.(*sessionRepo).db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		if b.Get([]byte("expired")) != nil {
+			t.Error("the expired session wasn't deleted")
+		}
+		if b.Get([]byte("corrupt")) != nil {
+			t.Error("the undecodable session wasn't deleted")
+		}
+		if b.Get([]byte("live")) == nil {
+			t.Error("the live session was deleted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
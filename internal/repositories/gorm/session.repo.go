@@ -6,7 +6,8 @@ import (
 	"time"
 )
 
-// SessionGormRepo implements session.Repository interface
+// SessionGormRepo implements session.Repository interface. It works with
+// the postgres and sqlite3 dialects.
 type sessionRepo struct {
 	db *gorm.DB
 }
@@ -19,9 +20,9 @@ func NewSessionRepo(db *gorm.DB) session.Repository {
 // GetSession returns a given stored session
 func (repo *sessionRepo) GetSession(sessionID string) (*session.Session, []error) {
 	s := session.Session{Data: make([]session.MapPair, 0)}
-	errs := repo.db.First(&s, "uuid=?", sessionID).GetErrors()
+	errs := repo.db.First(&s, "uuid = ? AND expires > ?", sessionID, time.Now()).GetErrors()
 	if len(errs) > 0 {
-		return nil, errs
+		return nil, notFound(errs)
 	}
 	err := repo.db.Model(&s).Association("Data").Find(&s.Data).Error
 	if err != nil {
@@ -45,9 +46,14 @@ func (repo *sessionRepo) DeleteSession(sessionID string) (*session.Session, []er
 	if len(errs) > 0 {
 		return nil, errs
 	}
-	errs = repo.db.Delete(s, "uuid=?", s.UUID).GetErrors()
-	if len(errs) > 0 {
-		return nil, errs
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(session.MapPair{}, "session_uuid = ?", s.UUID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(s, "uuid = ?", s.UUID).Error
+	})
+	if err != nil {
+		return nil, []error{err}
 	}
 	return s, errs
 }
@@ -56,16 +62,63 @@ func (repo *sessionRepo) DeleteSession(sessionID string) (*session.Session, []er
 // expired sessions every interval of the specified duration.
 func (repo *sessionRepo) StartSessionGC(duration time.Duration) {
 	time.AfterFunc(duration, func() {
-		_ = repo.db.Delete(session.Session{}, "expires<", time.Now())
+		_ = repo.db.Delete(session.Session{}, "expires < ?", time.Now())
+		_ = repo.db.Delete(session.MapPair{}, "session_uuid NOT IN (?)",
+			repo.db.Table("sessions").Select("uuid").SubQuery())
 		repo.StartSessionGC(duration)
 	})
 }
 
-// UpdateSession stores a given session
+// UpdateSession stores a given session. The data of the session is
+// replaced so that the keys deleted from it are removed.
+// session.ErrSessionNotFound is returned if it isn't stored or has expired.
 func (repo *sessionRepo) UpdateSession(s *session.Session) (*session.Session, []error) {
-	errs := repo.db.Save(s).GetErrors()
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		// the session is updated first which locks its row so that
+		// concurrent updates replace its data one after the other
+		updated := tx.Model(&session.Session{}).
+			Where("uuid = ? AND expires > ?", s.UUID, time.Now()).
+			Updates(map[string]interface{}{"expires": s.Expires, "last_access_time": s.LastAccessTime})
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return session.ErrSessionNotFound
+		}
+		if err := tx.Delete(session.MapPair{}, "session_uuid = ?", s.UUID).Error; err != nil {
+			return err
+		}
+		for i := range s.Data {
+			s.Data[i].SessionUUID = s.UUID
+			if err := tx.Create(&s.Data[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return s, nil
+}
+
+// notFound replaces gorm's record not found error by
+// session.ErrSessionNotFound.
+func notFound(errs []error) []error {
+	for i, err := range errs {
+		if gorm.IsRecordNotFoundError(err) {
+			errs[i] = session.ErrSessionNotFound
+		}
+	}
+	return errs
+}
+
+// CountSessions returns the number of sessions that haven't expired.
+func (repo *sessionRepo) CountSessions() (int, []error) {
+	var count int
+	errs := repo.db.Model(&session.Session{}).Where("expires > ?", time.Now()).Count(&count).GetErrors()
 	if len(errs) > 0 {
-		return nil, errs
+		return 0, errs
 	}
-	return s, errs
+	return count, nil
 }
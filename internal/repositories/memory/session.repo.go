@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+)
+
+// sessionRepo implements session.Repository keeping the sessions in
+// memory. Sessions are lost when the process exits and are evicted once
+// they expire.
+type sessionRepo struct {
+	mu       sync.RWMutex
+	sessions map[string]*session.Session
+}
+
+// NewSessionRepo returns a new in-memory session.Repository.
+func NewSessionRepo() session.Repository {
+	return &sessionRepo{sessions: make(map[string]*session.Session)}
+}
+
+// copySession returns a copy of the stored fields of s so that the
+// sessions held by the repository aren't shared with its callers.
+func copySession(s *session.Session) *session.Session {
+	return &session.Session{
+		UUID:           s.UUID,
+		Expires:        s.Expires,
+		LastAccessTime: s.LastAccessTime,
+		Data:           append(make([]session.MapPair, 0, len(s.Data)), s.Data...),
+	}
+}
+
+// GetSession returns a given stored session
+func (repo *sessionRepo) GetSession(sessionID string) (*session.Session, []error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	s, ok := repo.sessions[sessionID]
+	if !ok || !time.Now().Before(s.Expires) {
+		return nil, []error{session.ErrSessionNotFound}
+	}
+	return copySession(s), nil
+}
+
+// AddSession stores a given session
+func (repo *sessionRepo) AddSession(s *session.Session) (*session.Session, []error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.sessions[s.UUID] = copySession(s)
+	return s, nil
+}
+
+// UpdateSession stores a given session. session.ErrSessionNotFound is
+// returned if it isn't stored or has expired.
+func (repo *sessionRepo) UpdateSession(s *session.Session) (*session.Session, []error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	stored, ok := repo.sessions[s.UUID]
+	if !ok || !time.Now().Before(stored.Expires) {
+		return nil, []error{session.ErrSessionNotFound}
+	}
+	repo.sessions[s.UUID] = copySession(s)
+	return s, nil
+}
+
+// DeleteSession deletes a given session
+func (repo *sessionRepo) DeleteSession(sessionID string) (*session.Session, []error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	s, ok := repo.sessions[sessionID]
+	if !ok || !time.Now().Before(s.Expires) {
+		return nil, []error{session.ErrSessionNotFound}
+	}
+	delete(repo.sessions, sessionID)
+	return s, nil
+}
+
+// StartSessionGC launches an infinite recursive routine that evicts
+// expired sessions every interval of the specified duration.
+func (repo *sessionRepo) StartSessionGC(duration time.Duration) {
+	time.AfterFunc(duration, func() {
+		repo.evictExpired()
+		repo.StartSessionGC(duration)
+	})
+}
+
+func (repo *sessionRepo) evictExpired() {
+	now := time.Now()
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for id, s := range repo.sessions {
+		if !now.Before(s.Expires) {
+			delete(repo.sessions, id)
+		}
+	}
+}
+
+// CountSessions returns the number of sessions that haven't expired.
+func (repo *sessionRepo) CountSessions() (int, []error) {
+	now := time.Now()
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	count := 0
+	for _, s := range repo.sessions {
+		if now.Before(s.Expires) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+	"github.com/slim-crown/issue-1-website/internal/services/session/sessiontest"
+)
+
+func TestSessionRepo(t *testing.T) {
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		return NewSessionRepo()
+	})
+}
+
+func TestEvictExpired(t *testing.T) {
+	repo := NewSessionRepo().(*sessionRepo)
+	for id, expires := range map[string]time.Time{
+		"expired": time.Now().Add(-time.Minute),
+		"live":    time.Now().Add(time.Hour),
+	} {
+		repo.AddSession(&session.Session{UUID: id, Expires: expires})
+	}
+	repo.evictExpired()
+	if _, ok := repo.sessions["expired"]; ok {
+		t.Error("the expired session wasn't evicted")
+	}
+	if _, ok := repo.sessions["live"]; !ok {
+		t.Error("the live session was evicted")
+	}
+}
@@ -1,6 +1,14 @@
 package session
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
 
 // Service specifies logged in user session related service
 type Service interface {
@@ -11,7 +19,13 @@ type Service interface {
 	DeleteSession(sessionID string) (*Session, []error)
 }
 
-// Repository specifies logged in user session related database operations
+// ErrSessionNotFound is returned by repositories for sessions that don't
+// exist or have expired.
+var ErrSessionNotFound = errors.New("session: session not found")
+
+// Repository specifies logged in user session related database operations.
+// GetSession and DeleteSession return ErrSessionNotFound for sessions that
+// don't exist or have expired.
 type Repository interface {
 	GetSession(sessionID string) (*Session, []error)
 	AddSession(session *Session) (*Session, []error)
@@ -19,13 +33,52 @@ type Repository interface {
 	DeleteSession(sessionID string) (*Session, []error)
 }
 
+// Counter is implemented by repositories able to count the sessions that
+// haven't expired.
+type Counter interface {
+	CountSessions() (int, []error)
+}
+
+// Collector is implemented by repositories able to periodically remove the
+// sessions that have expired.
+type Collector interface {
+	StartSessionGC(interval time.Duration)
+}
+
 type service struct {
-	repo *Repository
+	repo   *Repository
+	logger *logging.Logger
+}
+
+// NewService  returns a new SessionService object. Failed operations are
+// logged with the given logger which may be nil.
+func NewService(r *Repository, logger *logging.Logger) Service {
+	return &service{repo: r, logger: logger}
 }
 
-// NewService  returns a new SessionService object
-func NewService(r *Repository) Service {
-	return &service{repo: r}
+// HashID returns a digest of the session ID safe to be logged in its place.
+func HashID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// logErrors logs the errors of a failed operation. Failures to get a
+// session are only logged at debug level as they're mostly due to expired
+// cookies.
+func (s *service) logErrors(op, sessionID string, errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+	fields := []logging.Field{
+		logging.F("op", op),
+		logging.F("session", HashID(sessionID)),
+		logging.F("errors", fmt.Sprint(errs)),
+	}
+	if op == "get" {
+		s.logger.Debug("session store operation failed", fields...)
+		return
+	}
+	s.logger.Warn("session store operation failed", fields...)
 }
 
 // NewSession returns a new session using the given sessionID.
@@ -46,10 +99,14 @@ func (s *service) NewSession(sessionID string, maxHardLifetime time.Duration) (*
 func (s *service) GetSession(sessionID string) (*Session, []error) {
 	sess, errs := (*s.repo).GetSession(sessionID)
 	if len(errs) > 0 {
+		s.logErrors("get", sessionID, errs)
 		return nil, errs
 	}
 	// use UpdateSession to refresh last access time
 	sess, errs = s.UpdateSession(sess)
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	sess.sessionService = s
 	sess.syncFromArrayToMap()
 	return sess, errs
@@ -57,16 +114,22 @@ func (s *service) GetSession(sessionID string) (*Session, []error) {
 
 // AddSession stores a given session
 func (s *service) AddSession(session *Session) (*Session, []error) {
-	return (*s.repo).AddSession(session)
+	sess, errs := (*s.repo).AddSession(session)
+	s.logErrors("add", session.UUID, errs)
+	return sess, errs
 }
 
 // UpdateSession stores a given session
 func (s *service) UpdateSession(session *Session) (*Session, []error) {
 	session.LastAccessTime = time.Now()
-	return (*s.repo).UpdateSession(session)
+	sess, errs := (*s.repo).UpdateSession(session)
+	s.logErrors("update", session.UUID, errs)
+	return sess, errs
 }
 
 // DeleteSession deletes a given session
 func (s *service) DeleteSession(sessionID string) (*Session, []error) {
-	return (*s.repo).DeleteSession(sessionID)
+	sess, errs := (*s.repo).DeleteSession(sessionID)
+	s.logErrors("delete", sessionID, errs)
+	return sess, errs
 }
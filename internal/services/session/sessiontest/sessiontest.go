@@ -0,0 +1,263 @@
+/*
+Package sessiontest provides a conformance test suite for the
+implementations of session.Repository so that every store behaves the same
+behind the session service. The suite is a table of scenarios, covering
+adding, getting, updating and deleting sessions, overwriting and deleting
+data keys, expiry, concurrent updates and the sessions not found, each run
+against a new repository.
+
+	func TestSessionRepo(t *testing.T) {
+		sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+			return NewSessionRepo()
+		})
+	}
+*/
+package sessiontest
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/internal/services/session"
+)
+
+// NewRepository returns the repository a scenario is run against. It must
+// be without sessions.
+type NewRepository func(t *testing.T) session.Repository
+
+// TestRepository runs every scenario of the suite against a repository
+// returned by newRepo.
+func TestRepository(t *testing.T, newRepo NewRepository) {
+	for _, sc := range scenarios {
+		sc := sc
+		t.Run(sc.name, func(t *testing.T) {
+			sc.test(t, newRepo(t))
+		})
+	}
+}
+
+// scenarios are the behaviors expected of every repository.
+var scenarios = []struct {
+	name string
+	test func(t *testing.T, repo session.Repository)
+}{
+	{"AddAndGet", func(t *testing.T, repo session.Repository) {
+		want := newSession("add", time.Hour, "username", "loveless", "csrf", "token")
+		add(t, repo, want)
+		assertStored(t, repo, want)
+	}},
+	{"AddWithoutData", func(t *testing.T, repo session.Repository) {
+		want := newSession("empty", time.Hour)
+		add(t, repo, want)
+		assertStored(t, repo, want)
+	}},
+	{"Update", func(t *testing.T, repo session.Repository) {
+		want := newSession("update", time.Hour, "username", "loveless")
+		add(t, repo, want)
+		want.LastAccessTime = want.LastAccessTime.Add(time.Minute)
+		want.Data = append(want.Data, pairs(want.UUID, "csrf", "token")...)
+		update(t, repo, want)
+		assertStored(t, repo, want)
+	}},
+	{"DataKeyOverwrite", func(t *testing.T, repo session.Repository) {
+		want := newSession("overwrite", time.Hour, "username", "loveless", "csrf", "token")
+		add(t, repo, want)
+		want.Data = pairs(want.UUID, "username", "randoWanda", "csrf", "token")
+		update(t, repo, want)
+		assertStored(t, repo, want)
+	}},
+	{"DataKeyDeletion", func(t *testing.T, repo session.Repository) {
+		want := newSession("deletion", time.Hour, "username", "loveless", "csrf", "token")
+		add(t, repo, want)
+		want.Data = pairs(want.UUID, "csrf", "token")
+		update(t, repo, want)
+		assertStored(t, repo, want)
+		want.Data = pairs(want.UUID)
+		update(t, repo, want)
+		assertStored(t, repo, want)
+	}},
+	{"Delete", func(t *testing.T, repo session.Repository) {
+		want := newSession("delete", time.Hour, "username", "loveless")
+		add(t, repo, want)
+		got, errs := repo.DeleteSession(want.UUID)
+		if len(errs) > 0 {
+			t.Fatalf("DeleteSession failed: %v", errs)
+		}
+		if got.UUID != want.UUID {
+			t.Errorf("DeleteSession returned %q, want %q", got.UUID, want.UUID)
+		}
+		assertNotFound(t, repo, want.UUID)
+	}},
+	{"DeleteRemovesData", func(t *testing.T, repo session.Repository) {
+		deleted := newSession("reused", time.Hour, "username", "loveless")
+		add(t, repo, deleted)
+		if _, errs := repo.DeleteSession(deleted.UUID); len(errs) > 0 {
+			t.Fatalf("DeleteSession failed: %v", errs)
+		}
+		want := newSession("reused", time.Hour)
+		add(t, repo, want)
+		assertStored(t, repo, want)
+	}},
+	{"DeleteLeavesOtherSessions", func(t *testing.T, repo session.Repository) {
+		want := newSession("kept", time.Hour, "username", "loveless")
+		add(t, repo, want)
+		add(t, repo, newSession("deleted", time.Hour, "username", "loveless"))
+		if _, errs := repo.DeleteSession("deleted"); len(errs) > 0 {
+			t.Fatalf("DeleteSession failed: %v", errs)
+		}
+		assertStored(t, repo, want)
+	}},
+	{"NotFound", func(t *testing.T, repo session.Repository) {
+		assertNotFound(t, repo, "missing")
+		if _, errs := repo.DeleteSession("missing"); !hasErr(errs, session.ErrSessionNotFound) {
+			t.Errorf("DeleteSession of a missing session returned %v, want ErrSessionNotFound", errs)
+		}
+	}},
+	{"DeleteTwice", func(t *testing.T, repo session.Repository) {
+		add(t, repo, newSession("twice", time.Hour))
+		if _, errs := repo.DeleteSession("twice"); len(errs) > 0 {
+			t.Fatalf("DeleteSession failed: %v", errs)
+		}
+		if _, errs := repo.DeleteSession("twice"); !hasErr(errs, session.ErrSessionNotFound) {
+			t.Errorf("DeleteSession of a deleted session returned %v, want ErrSessionNotFound", errs)
+		}
+	}},
+	{"UpdateMissing", func(t *testing.T, repo session.Repository) {
+		deleted := newSession("deleted", time.Hour, "username", "loveless")
+		add(t, repo, deleted)
+		if _, errs := repo.DeleteSession(deleted.UUID); len(errs) > 0 {
+			t.Fatalf("DeleteSession failed: %v", errs)
+		}
+		for _, s := range []*session.Session{newSession("missing", time.Hour), deleted} {
+			if _, errs := repo.UpdateSession(s); !hasErr(errs, session.ErrSessionNotFound) {
+				t.Errorf("UpdateSession(%q) returned %v, want ErrSessionNotFound", s.UUID, errs)
+			}
+			assertNotFound(t, repo, s.UUID)
+		}
+	}},
+	{"Expired", func(t *testing.T, repo session.Repository) {
+		add(t, repo, newSession("expired", -time.Minute, "username", "loveless"))
+		add(t, repo, newSession("live", time.Hour))
+		assertNotFound(t, repo, "expired")
+		if _, errs := repo.DeleteSession("expired"); !hasErr(errs, session.ErrSessionNotFound) {
+			t.Errorf("DeleteSession of an expired session returned %v, want ErrSessionNotFound", errs)
+		}
+		if _, errs := repo.UpdateSession(newSession("expired", time.Hour)); !hasErr(errs, session.ErrSessionNotFound) {
+			t.Errorf("UpdateSession of an expired session returned %v, want ErrSessionNotFound", errs)
+		}
+		if counter, ok := repo.(session.Counter); ok {
+			if count, errs := counter.CountSessions(); len(errs) > 0 || count != 1 {
+				t.Errorf("CountSessions = %d, %v, want 1 session", count, errs)
+			}
+		}
+	}},
+	{"ConcurrentUpdates", func(t *testing.T, repo session.Repository) {
+		const writers = 8
+		add(t, repo, newSession("concurrent", time.Hour, "writer", "none"))
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				writer := strconv.Itoa(i)
+				s := newSession("concurrent", time.Hour, "writer", writer, "data"+writer, writer)
+				if _, errs := repo.UpdateSession(s); len(errs) > 0 {
+					t.Errorf("UpdateSession by writer %d failed: %v", i, errs)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// the data of a single update is stored, not a mix of them
+		got, errs := repo.GetSession("concurrent")
+		if len(errs) > 0 {
+			t.Fatalf("GetSession failed: %v", errs)
+		}
+		data := make(map[string]string, len(got.Data))
+		for _, p := range got.Data {
+			data[p.Key] = p.Value
+		}
+		writer := data["writer"]
+		if len(data) != 2 || data["data"+writer] != writer {
+			t.Errorf("GetSession data = %v, want the data of a single writer", got.Data)
+		}
+	}},
+}
+
+// newSession returns a session expiring after the given duration holding
+// the given key-value pairs. Times are truncated to milliseconds which every
+// store keeps.
+func newSession(id string, expiresAfter time.Duration, keyValues ...string) *session.Session {
+	now := time.Now().Truncate(time.Millisecond)
+	return &session.Session{
+		UUID:           id,
+		Expires:        now.Add(expiresAfter),
+		LastAccessTime: now,
+		Data:           pairs(id, keyValues...),
+	}
+}
+
+func pairs(id string, keyValues ...string) []session.MapPair {
+	data := make([]session.MapPair, 0, len(keyValues)/2)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		data = append(data, session.MapPair{SessionUUID: id, Key: keyValues[i], Value: keyValues[i+1]})
+	}
+	return data
+}
+
+func add(t *testing.T, repo session.Repository, s *session.Session) {
+	t.Helper()
+	if _, errs := repo.AddSession(s); len(errs) > 0 {
+		t.Fatalf("AddSession(%q) failed: %v", s.UUID, errs)
+	}
+}
+
+func update(t *testing.T, repo session.Repository, s *session.Session) {
+	t.Helper()
+	if _, errs := repo.UpdateSession(s); len(errs) > 0 {
+		t.Fatalf("UpdateSession(%q) failed: %v", s.UUID, errs)
+	}
+}
+
+// assertStored fails the test if the stored session differs from want.
+// The order of the data isn't significant.
+func assertStored(t *testing.T, repo session.Repository, want *session.Session) {
+	t.Helper()
+	got, errs := repo.GetSession(want.UUID)
+	if len(errs) > 0 {
+		t.Fatalf("GetSession(%q) failed: %v", want.UUID, errs)
+	}
+	if got.UUID != want.UUID || !got.Expires.Equal(want.Expires) || !got.LastAccessTime.Equal(want.LastAccessTime) {
+		t.Errorf("GetSession(%q) = %+v, want %+v", want.UUID, got, want)
+	}
+	if !reflect.DeepEqual(sorted(got.Data), sorted(want.Data)) {
+		t.Errorf("GetSession(%q) data = %v, want %v", want.UUID, got.Data, want.Data)
+	}
+}
+
+func assertNotFound(t *testing.T, repo session.Repository, sessionID string) {
+	t.Helper()
+	if s, errs := repo.GetSession(sessionID); !hasErr(errs, session.ErrSessionNotFound) {
+		t.Errorf("GetSession(%q) = %v, %v, want ErrSessionNotFound", sessionID, s, errs)
+	}
+}
+
+func hasErr(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func sorted(data []session.MapPair) []session.MapPair {
+	data = append(make([]session.MapPair, 0, len(data)), data...)
+	sort.Slice(data, func(i, j int) bool { return data[i].Key < data[j].Key })
+	return data
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Exporter receives the spans that ended.
+type Exporter interface {
+	ExportSpan(span *SpanData)
+}
+
+// WriterExporter writes the spans as JSON, one per line, to a writer for
+// offline analysis.
+type WriterExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	c   io.Closer
+}
+
+// NewWriterExporter returns an exporter writing the spans to w.
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{enc: json.NewEncoder(w)}
+}
+
+// NewFileExporter returns an exporter appending the spans to the file at
+// the given path, creating it if need be. It must be closed.
+func NewFileExporter(path string) (*WriterExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	e := NewWriterExporter(f)
+	e.c = f
+	return e, nil
+}
+
+// ExportSpan writes the span. Spans that fail to be written are dropped.
+func (e *WriterExporter) ExportSpan(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_ = e.enc.Encode(span)
+}
+
+// Close closes the file of exporters returned by NewFileExporter.
+func (e *WriterExporter) Close() error {
+	if e.c == nil {
+		return nil
+	}
+	return e.c.Close()
+}
@@ -0,0 +1,154 @@
+package tracing
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// Handler returns a handler that traces the requests served by next with
+// spans named after the given route. The traceparent header of the requests
+// is honored. The spans are available to next through SpanFromContext.
+func Handler(t *Tracer, route string, next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if sc, ok := ParseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+			ctx = ContextWithRemoteSpanContext(ctx, sc)
+		}
+		ctx, span := t.Start(ctx, route)
+		defer span.End()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.target", r.URL.Path)
+
+		rec := NewResponseRecorder(w)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttribute("http.status_code", rec.Status)
+		span.SetAttribute("http.response_bytes", rec.Bytes)
+	})
+}
+
+// ResponseRecorder is a http.ResponseWriter recording the status and the
+// size of the response written through it.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	// Status is the status of the response, http.StatusOK until one is
+	// written.
+	Status int
+	// Bytes is the size of the body written so far.
+	Bytes       int64
+	wroteHeader bool
+}
+
+// NewResponseRecorder returns a ResponseRecorder writing to w. w is
+// returned if it's already a ResponseRecorder so that the handlers wrapping
+// one another share it instead of each wrapping the response again.
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	if rr, ok := w.(*ResponseRecorder); ok {
+		return rr
+	}
+	return &ResponseRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+// WriteHeader records the status unless the header was already written.
+func (rr *ResponseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.Status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the size of b.
+func (rr *ResponseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	n, err := rr.ResponseWriter.Write(b)
+	rr.Bytes += int64(n)
+	return n, err
+}
+
+// Flush sends the buffered response to the client if w supports it.
+func (rr *ResponseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		rr.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Middleware returns issue1.Middleware tracing every request sent to the
+// REST server with spans that are children of the span carried by the
+// context of the request. The span context is propagated to the REST server
+// with the traceparent header. Spans end once the response body is read or
+// closed.
+func Middleware(t *Tracer) issue1.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if t == nil {
+			return next
+		}
+		return issue1.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := t.Start(req.Context(), "issue1 "+req.Method+" "+req.URL.Path)
+			span.SetAttribute("rest.method", req.Method)
+			span.SetAttribute("rest.path", req.URL.Path)
+			if endpoint := issue1.EndpointFromContext(req.Context()); endpoint != "" {
+				span.SetAttribute("rest.endpoint", endpoint)
+			}
+			if req.ContentLength > 0 {
+				span.SetAttribute("rest.request_bytes", req.ContentLength)
+			}
+			if id := req.Header.Get(issue1.RequestIDHeader); id != "" {
+				span.SetAttribute("request_id", id)
+			}
+
+			// RoundTrippers mustn't modify the request they're given
+			req = req.Clone(ctx)
+			req.Header.Set(TraceparentHeader, span.SpanContext().Traceparent())
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.SetError(err)
+				span.End()
+				return resp, err
+			}
+			span.SetAttribute("rest.status_code", resp.StatusCode)
+			resp.Body = &tracedBody{ReadCloser: resp.Body, span: span}
+			return resp, nil
+		})
+	}
+}
+
+// tracedBody ends the span of a response once it's read or closed.
+type tracedBody struct {
+	io.ReadCloser
+	span  *Span
+	bytes int64
+	once  sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err != nil {
+		if err != io.EOF {
+			b.span.SetError(err)
+		}
+		b.end()
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end()
+	return err
+}
+
+func (b *tracedBody) end() {
+	b.once.Do(func() {
+		b.span.SetAttribute("rest.response_bytes", b.bytes)
+		b.span.End()
+	})
+}
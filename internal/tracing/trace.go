@@ -0,0 +1,233 @@
+// Package tracing records trace spans of the requests served by the website
+// and the requests it makes to the REST server. Spans are propagated to the
+// REST server using W3C traceparent headers and handed to an Exporter once
+// they end.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, i.e. all the spans caused by a request.
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is what's propagated to identify a span across processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether neither the trace nor the span ID is zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceparentHeader is the header spans are propagated with.
+const TraceparentHeader = "traceparent"
+
+// Traceparent returns the value of the traceparent header identifying the
+// span context.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses the value of a traceparent header. The returned
+// bool is false if it's malformed.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, false
+	}
+	// later versions may append fields, version 00 mustn't
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, false
+	}
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) {
+		return sc, false
+	}
+	var flags [1]byte
+	if !decodeHex(flags[:], parts[3]) {
+		return sc, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Span is a timed operation of a trace. Its methods are safe to call on a
+// nil Span so that code doesn't have to check whether it's traced.
+type Span struct {
+	tracer *Tracer
+	data   SpanData
+	mu     sync.Mutex
+	ended  bool
+}
+
+// SpanData is what's exported of a span once it ends.
+type SpanData struct {
+	Name         string                 `json:"name"`
+	TraceID      string                 `json:"traceID"`
+	SpanID       string                 `json:"spanID"`
+	ParentSpanID string                 `json:"parentSpanID,omitempty"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Duration     float64                `json:"durationMs"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+
+	spanContext SpanContext
+}
+
+// SpanContext returns the span context of the span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.spanContext
+}
+
+// SetAttribute sets an attribute of the span. Attributes set after the span
+// ended are dropped.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]interface{})
+	}
+	s.data.Attributes[key] = value
+}
+
+// SetError records that the operation of the span failed.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Error = err.Error()
+	}
+}
+
+// End ends the span and exports it if it's sampled. Only the first call has
+// an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.timeNow()
+	s.data.Duration = float64(s.data.End.Sub(s.data.Start)) / float64(time.Millisecond)
+	data := s.data
+	s.mu.Unlock()
+	if data.spanContext.Sampled && s.tracer.Exporter != nil {
+		s.tracer.Exporter.ExportSpan(&data)
+	}
+}
+
+// Tracer starts spans and hands them to its Exporter once they end. A nil
+// Tracer starts no spans.
+type Tracer struct {
+	Exporter Exporter
+
+	now func() time.Time
+}
+
+// NewTracer returns a Tracer exporting spans to the given exporter.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{Exporter: exporter}
+}
+
+type spanKey struct{}
+
+type remoteSpanKey struct{}
+
+// Start starts a span that's the child of the span carried by ctx, or of the
+// remote span carried by it, and returns a copy of ctx carrying the new span.
+// A new trace is started if it carries neither. The span must be ended.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	s := &Span{tracer: t}
+	s.data.Name = name
+	s.data.Start = t.timeNow()
+
+	parent := SpanFromContext(ctx).SpanContext()
+	if !parent.IsValid() {
+		parent, _ = ctx.Value(remoteSpanKey{}).(SpanContext)
+	}
+	if parent.IsValid() {
+		s.data.spanContext.TraceID = parent.TraceID
+		s.data.spanContext.Sampled = parent.Sampled
+		s.data.ParentSpanID = parent.SpanID.String()
+	} else {
+		_, _ = rand.Read(s.data.spanContext.TraceID[:])
+		s.data.spanContext.Sampled = true
+	}
+	_, _ = rand.Read(s.data.spanContext.SpanID[:])
+	s.data.TraceID = s.data.spanContext.TraceID.String()
+	s.data.SpanID = s.data.spanContext.SpanID.String()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *Tracer) timeNow() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// SpanFromContext returns the span carried by ctx, nil if there's none.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying the span
+// context of a span of another process which the spans started with it
+// become children of.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanKey{}, sc)
+}
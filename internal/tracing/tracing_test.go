@@ -0,0 +1,154 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+func (r *recorder) ExportSpan(span *SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(valid)
+	if !ok || !sc.Sampled {
+		t.Fatalf("ParseTraceparent(%q) = %+v, %v", valid, sc, ok)
+	}
+	if got := sc.Traceparent(); got != valid {
+		t.Errorf("Traceparent() = %s, want %s", got, valid)
+	}
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if sc, ok := ParseTraceparent(value); ok {
+			t.Errorf("ParseTraceparent(%q) = %+v, want it rejected", value, sc)
+		}
+	}
+}
+
+func TestTracing(t *testing.T) {
+	var gotTraceparent string
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get(TraceparentHeader)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless"}}`))
+	}))
+	defer rest.Close()
+
+	rec := new(recorder)
+	tracer := NewTracer(rec)
+	baseURL, _ := url.Parse(rest.URL)
+	c := issue1.NewClient(rest.Client(), baseURL, nil)
+	c.Use(Middleware(tracer))
+
+	handler := Handler(tracer, "GET /u/:username", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SpanFromContext(r.Context()).SetAttribute("username", "loveless")
+		if _, err := c.UserService.GetUserContext(r.Context(), "loveless"); err != nil {
+			t.Errorf("GetUser() returned error %s", err)
+		}
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("only shallow"))
+	}))
+	req := httptest.NewRequest("GET", "/u/loveless", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("%d spans were exported, want 2", len(rec.spans))
+	}
+	restSpan, routeSpan := rec.spans[0], rec.spans[1]
+	if routeSpan.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || routeSpan.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("route span didn't continue the incoming trace: %+v", routeSpan)
+	}
+	if restSpan.TraceID != routeSpan.TraceID || restSpan.ParentSpanID != routeSpan.SpanID {
+		t.Errorf("rest span isn't a child of the route span: %+v", restSpan)
+	}
+	if want := "00-" + restSpan.TraceID + "-" + restSpan.SpanID + "-01"; gotTraceparent != want {
+		t.Errorf("traceparent sent = %s, want %s", gotTraceparent, want)
+	}
+
+	// compare the attributes as they're written by the exporter
+	for _, tc := range []struct {
+		span *SpanData
+		want map[string]interface{}
+	}{
+		{routeSpan, map[string]interface{}{
+			"http.method": "GET", "http.route": "GET /u/:username", "http.target": "/u/loveless",
+			"http.status_code": 418.0, "http.response_bytes": 12.0, "username": "loveless",
+		}},
+		{restSpan, map[string]interface{}{
+			"rest.method": "GET", "rest.path": "/users/loveless", "rest.endpoint": "UserService.GetUser",
+			"rest.status_code": 200.0, "rest.response_bytes": 51.0,
+		}},
+	} {
+		b := new(bytes.Buffer)
+		NewWriterExporter(b).ExportSpan(tc.span)
+		var got struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		for key, want := range tc.want {
+			if got.Attributes[key] != want {
+				t.Errorf("%s: %s = %v, want %v", tc.span.Name, key, got.Attributes[key], want)
+			}
+		}
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(httptest.NewRequest("GET", "/", nil).Context(), "nothing")
+	span.SetAttribute("username", "loveless")
+	span.End()
+	if SpanFromContext(ctx) != nil {
+		t.Error("a nil tracer started a span")
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	outer := NewResponseRecorder(w)
+	var inner *ResponseRecorder
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		inner = NewResponseRecorder(rw)
+		inner.WriteHeader(http.StatusAccepted)
+		_, _ = inner.Write([]byte("only shallow"))
+		f, ok := rw.(http.Flusher)
+		if !ok {
+			t.Fatal("the recorder hides http.Flusher")
+		}
+		f.Flush()
+	})
+	handler.ServeHTTP(outer, httptest.NewRequest("GET", "/", nil))
+
+	if inner != outer {
+		t.Error("a recorder was wrapped in another one")
+	}
+	if outer.Status != http.StatusAccepted || outer.Bytes != 12 {
+		t.Errorf("Status = %d, Bytes = %d, want %d and 12", outer.Status, outer.Bytes, http.StatusAccepted)
+	}
+	if !w.Flushed {
+		t.Error("Flush wasn't forwarded")
+	}
+}
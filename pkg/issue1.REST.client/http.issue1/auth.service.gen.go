@@ -0,0 +1,65 @@
+// Code generated by issue1gen from endpoints.json. DO NOT EDIT.
+
+package issue1
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetAuthToken gets an JWT auth token using the provided credentials.
+func (s *AuthService) GetAuthToken(username, password string) (string, error) {
+	return s.GetAuthTokenContext(context.Background(), username, password)
+}
+
+// GetAuthTokenContext is the same as GetAuthToken but it uses the given context for the request.
+func (s *AuthService) GetAuthTokenContext(ctx context.Context, username, password string) (string, error) {
+	req := s.client.newRequest(ctx, "/token-auth", http.MethodPost)
+	if err := addBodyToRequestAsJSON(req, map[string]interface{}{"password": password, "username": username}); err != nil {
+		return "", err
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	err := s.client.call(req, "AuthService.GetAuthToken", []failure{
+		{http.StatusUnauthorized, "", ErrCredentialsUnaccepted},
+	}, &out)
+	if err != nil {
+		return "", err
+	}
+	return out.Token, nil
+}
+
+// RefreshAuthToken gets a new token using the passed in token. If the passed
+// in token is too old, it will throw ErrAccessDenied.
+func (s *AuthService) RefreshAuthToken(token string) (string, error) {
+	return s.RefreshAuthTokenContext(context.Background(), token)
+}
+
+// RefreshAuthTokenContext is the same as RefreshAuthToken but it uses the given context for the request.
+func (s *AuthService) RefreshAuthTokenContext(ctx context.Context, token string) (string, error) {
+	req := s.client.newRequest(ctx, "/token-auth-refresh", http.MethodGet)
+	addJWTToRequest(req, token)
+	var out struct {
+		Token string `json:"token"`
+	}
+	err := s.client.call(req, "AuthService.RefreshAuthToken", []failure{
+		{http.StatusUnauthorized, "", ErrAccessDenied},
+	}, &out)
+	if err != nil {
+		return "", err
+	}
+	return out.Token, nil
+}
+
+// Logout invalidates the passed in token from further usage.
+func (s *AuthService) Logout(token string) error {
+	return s.LogoutContext(context.Background(), token)
+}
+
+// LogoutContext is the same as Logout but it uses the given context for the request.
+func (s *AuthService) LogoutContext(ctx context.Context, token string) error {
+	req := s.client.newRequest(ctx, "/logout", http.MethodGet)
+	addJWTToRequest(req, token)
+	return s.client.call(req, "AuthService.Logout", nil, nil)
+}
@@ -0,0 +1,46 @@
+package issue1
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the number of requests sent at the same time
+// by the batch methods if the client's BatchConcurrency isn't set.
+const DefaultBatchConcurrency = 8
+
+// batch calls fn for every index below n using a bounded number of
+// goroutines and returns the errors in the same order. Indices that weren't
+// processed because the context got done get the context's error.
+func (c *Client) batch(ctx context.Context, n int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	workers := c.BatchConcurrency
+	if workers <= 0 {
+		workers = DefaultBatchConcurrency
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				errs[i] = fn(ctx, i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return errs
+}
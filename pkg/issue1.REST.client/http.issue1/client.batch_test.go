@@ -0,0 +1,64 @@
+package issue1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetReleasesBatch(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		id := strings.TrimPrefix(r.URL.Path, "/releases/")
+		if id == "4" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":"fail","data":{"errorReason":"releaseID"}}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"id":%s}}`, id)
+	})
+	defer done()
+	c.BatchConcurrency = 3
+
+	ids := []uint{9, 8, 7, 6, 5, 4, 3, 2, 1}
+	releases, errs := c.ReleaseService.GetReleasesBatch(ids)
+	if len(releases) != len(ids) || len(errs) != len(ids) {
+		t.Fatalf("len(releases) = %d, len(errs) = %d, want %d", len(releases), len(errs), len(ids))
+	}
+	for i, id := range ids {
+		if id == 4 {
+			if releases[i] != nil || !errors.Is(errs[i], ErrReleaseNotFound) {
+				t.Errorf("index %d: release = %v, err = %v, want %v", i, releases[i], errs[i], ErrReleaseNotFound)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("index %d: err = %v", i, errs[i])
+			continue
+		}
+		if releases[i].ID != id {
+			t.Errorf("index %d: ID = %d, want %d", i, releases[i].ID, id)
+		}
+	}
+	if maxInFlight > c.BatchConcurrency {
+		t.Errorf("%d requests were in flight, want at most %d", maxInFlight, c.BatchConcurrency)
+	}
+}
@@ -0,0 +1,220 @@
+package issue1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a request isn't sent because the circuit
+// of its endpoint group is open, i.e. the REST server was failing requests
+// of that group and is being given time to recover.
+var ErrCircuitOpen = errors.New("http.issue1: circuit open, rest server unavailable")
+
+// CircuitState is the state of a circuit of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all requests immediately with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through. The circuit is
+	// closed if the probe succeeds and opened again if it doesn't.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops the client from sending requests to the REST server
+// after consecutive failures. Requests are grouped by the first segment of
+// their path (e.g. "users", "channels", "posts") and every group has its own
+// circuit so that a failing endpoint doesn't take down the others.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures after which
+	// the circuit of a group opens.
+	FailureThreshold int
+	// GroupThresholds overrides the FailureThreshold for specific groups.
+	GroupThresholds map[string]int
+	// CoolDown is how long a circuit stays open before a probe request
+	// is let through.
+	CoolDown time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a CircuitBreaker that opens the circuit of a
+// group after failureThreshold consecutive failures and keeps it open for
+// the coolDown duration.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		GroupThresholds:  make(map[string]int),
+		CoolDown:         coolDown,
+	}
+}
+
+// State returns the current state of the circuit of the given group.
+func (cb *CircuitBreaker) State(group string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state(cb.circuit(group))
+}
+
+// States returns the state of the circuits of all the groups that have
+// been used so far.
+func (cb *CircuitBreaker) States() map[string]CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	states := make(map[string]CircuitState, len(cb.circuits))
+	for group, c := range cb.circuits {
+		states[group] = cb.state(c)
+	}
+	return states
+}
+
+// Degraded reports whether the circuit of any group is open.
+func (cb *CircuitBreaker) Degraded() bool {
+	for _, state := range cb.States() {
+		if state == CircuitOpen {
+			return true
+		}
+	}
+	return false
+}
+
+// allow returns ErrCircuitOpen if a request of the given group shouldn't
+// be sent.
+func (cb *CircuitBreaker) allow(group string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(group)
+	switch cb.state(c) {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if c.probing {
+			return ErrCircuitOpen
+		}
+		c.probing = true
+	}
+	return nil
+}
+
+// record reports the outcome of a request of the given group that
+// was allowed through.
+func (cb *CircuitBreaker) record(group string, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(group)
+	wasProbe := c.probing
+	c.probing = false
+	if !failed {
+		c.state = CircuitClosed
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if wasProbe || c.failures >= cb.threshold(group) {
+		c.state = CircuitOpen
+		c.openedAt = cb.timeNow()
+	}
+}
+
+// abandon is used instead of record when the outcome of a request of the
+// given group that was allowed through is unknown.
+func (cb *CircuitBreaker) abandon(group string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.circuit(group).probing = false
+}
+
+// state returns the state of the given circuit taking the cool down into
+// account. cb.mu must be held.
+func (cb *CircuitBreaker) state(c *circuit) CircuitState {
+	if c.state == CircuitOpen && cb.timeNow().Sub(c.openedAt) >= cb.CoolDown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// circuit returns the circuit of the given group, creating it if need be.
+// cb.mu must be held.
+func (cb *CircuitBreaker) circuit(group string) *circuit {
+	if cb.circuits == nil {
+		cb.circuits = make(map[string]*circuit)
+	}
+	c, ok := cb.circuits[group]
+	if !ok {
+		c = &circuit{}
+		cb.circuits[group] = c
+	}
+	return c
+}
+
+func (cb *CircuitBreaker) threshold(group string) int {
+	if threshold, ok := cb.GroupThresholds[group]; ok && threshold > 0 {
+		return threshold
+	}
+	if cb.FailureThreshold > 0 {
+		return cb.FailureThreshold
+	}
+	return 1
+}
+
+func (cb *CircuitBreaker) timeNow() time.Time {
+	if cb.now != nil {
+		return cb.now()
+	}
+	return time.Now()
+}
+
+// Degraded reports whether the REST server is failing requests and the
+// client is refusing to send some of them. It's always false if the
+// client doesn't have a CircuitBreaker.
+func (c *Client) Degraded() bool {
+	return c.CircuitBreaker != nil && c.CircuitBreaker.Degraded()
+}
+
+// endpointGroup returns the group the request belongs to which is the
+// first segment of its path relative to the BaseURL.
+func (c *Client) endpointGroup(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(c.BaseURL.Path, "/"))
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		path = path[:i]
+	}
+	return strings.ToLower(path)
+}
+
+// isServerFailure reports whether the response indicates the REST server
+// itself is failing as opposed to it rejecting the request.
+func isServerFailure(statusCode int, js *jSendResponse) bool {
+	if statusCode < http.StatusInternalServerError {
+		return false
+	}
+	// jSend fail responses are the server rejecting the request
+	return js == nil || js.Status != "fail"
+}
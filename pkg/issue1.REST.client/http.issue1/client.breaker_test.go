@@ -0,0 +1,103 @@
+package issue1
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	down := true
+	requests := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if down && strings.HasPrefix(r.URL.Path, "/users") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"status":"error","message":"database unreachable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless"}}`))
+	})
+	defer done()
+
+	now := time.Now()
+	c.CircuitBreaker = NewCircuitBreaker(2, time.Minute)
+	c.CircuitBreaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.UserService.GetUser("loveless"); !errors.Is(err, ErrRESTServerError) {
+			t.Fatalf("attempt %d: err = %v, want %v", i+1, err, ErrRESTServerError)
+		}
+	}
+	if got := c.CircuitBreaker.State("users"); got != CircuitOpen {
+		t.Fatalf("state = %v, want %v", got, CircuitOpen)
+	}
+	if !c.Degraded() {
+		t.Error("Degraded() = false with an open circuit")
+	}
+
+	requests = 0
+	if _, err := c.UserService.GetUser("loveless"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want %v", err, ErrCircuitOpen)
+	}
+	if requests != 0 {
+		t.Errorf("%d requests were sent through an open circuit", requests)
+	}
+	if _, err := c.ChannelService.GetChannel("chromagnum"); err != nil {
+		t.Errorf("other groups were affected: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if got := c.CircuitBreaker.State("users"); got != CircuitHalfOpen {
+		t.Fatalf("state after cool down = %v, want %v", got, CircuitHalfOpen)
+	}
+	if c.Degraded() {
+		t.Error("Degraded() = true with a half-open circuit")
+	}
+	if _, err := c.UserService.GetUser("loveless"); !errors.Is(err, ErrRESTServerError) {
+		t.Fatalf("probe err = %v, want %v", err, ErrRESTServerError)
+	}
+	if got := c.CircuitBreaker.State("users"); got != CircuitOpen {
+		t.Fatalf("state after failed probe = %v, want %v", got, CircuitOpen)
+	}
+
+	down = false
+	now = now.Add(time.Minute)
+	if _, err := c.UserService.GetUser("loveless"); err != nil {
+		t.Fatalf("probe err = %v", err)
+	}
+	if got := c.CircuitBreaker.State("users"); got != CircuitClosed {
+		t.Errorf("state after successful probe = %v, want %v", got, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status":"error","message":"database unreachable"}`))
+	})
+	defer done()
+	c.CircuitBreaker = NewCircuitBreaker(2, time.Minute)
+	c.MaxImageSize = int64(len(png)) - 1
+
+	if _, err := c.UserService.GetUser("loveless"); !errors.Is(err, ErrRESTServerError) {
+		t.Fatalf("err = %v, want %v", err, ErrRESTServerError)
+	}
+	// an upload aborted on our side must not reset the failures counted
+	// so far
+	_, err := c.UserService.AddPicture("loveless", onlyReader{bytes.NewReader(png)}, "loveless.png", "token")
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("err = %v, want %v", err, ErrImageTooLarge)
+	}
+	if _, err := c.UserService.GetUser("loveless"); !errors.Is(err, ErrRESTServerError) {
+		t.Fatalf("err = %v, want %v", err, ErrRESTServerError)
+	}
+	if got := c.CircuitBreaker.State("users"); got != CircuitOpen {
+		t.Errorf("state = %v, want %v", got, CircuitOpen)
+	}
+}
@@ -0,0 +1,244 @@
+package issue1
+
+import (
+	"bytes"
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache caches the responses of GET requests in memory. Responses
+// are cached per endpoint group (the first segment of the path, e.g. "users")
+// for the duration given in TTLs and requests of groups missing from it are
+// never cached. Expired responses that came with an ETag are revalidated
+// using If-None-Match instead of being fetched again.
+//
+// Entries are cached under the URL and the Authorization header of the
+// request so responses are never shared between users. The entries under
+// a path are dropped whenever the client sends a request mutating it.
+type ResponseCache struct {
+	// TTLs maps endpoint groups to how long their responses are fresh.
+	TTLs map[string]time.Duration
+	// MaxEntries is the number of responses kept after which the least
+	// recently used ones are evicted. Zero means no limit.
+	MaxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key     string
+	path    string
+	status  int
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// NewResponseCache returns a ResponseCache that holds up to maxEntries
+// responses of the users, posts, releases and channels endpoints.
+func NewResponseCache(maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		TTLs: map[string]time.Duration{
+			"users":    time.Minute,
+			"posts":    30 * time.Second,
+			"releases": 5 * time.Minute,
+			"channels": time.Minute,
+		},
+		MaxEntries: maxEntries,
+	}
+}
+
+// Invalidate drops the cached responses of all the URLs under the given path.
+func (rc *ResponseCache) Invalidate(path string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	path = strings.TrimSuffix(path, "/")
+	for key, el := range rc.entries {
+		p := el.Value.(*cacheEntry).path
+		if p == path || strings.HasPrefix(p, path+"/") {
+			rc.lru.Remove(el)
+			delete(rc.entries, key)
+		}
+	}
+}
+
+// Purge drops all the cached responses.
+func (rc *ResponseCache) Purge() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.lru = nil
+	rc.entries = nil
+}
+
+// Len returns the number of cached responses.
+func (rc *ResponseCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.entries)
+}
+
+func (rc *ResponseCache) get(key string) (cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	el, ok := rc.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	rc.lru.MoveToFront(el)
+	return *el.Value.(*cacheEntry), true
+}
+
+func (rc *ResponseCache) put(entry *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.entries == nil {
+		rc.entries = make(map[string]*list.Element)
+		rc.lru = list.New()
+	}
+	if el, ok := rc.entries[entry.key]; ok {
+		el.Value = entry
+		rc.lru.MoveToFront(el)
+		return
+	}
+	rc.entries[entry.key] = rc.lru.PushFront(entry)
+	for rc.MaxEntries > 0 && rc.lru.Len() > rc.MaxEntries {
+		oldest := rc.lru.Back()
+		rc.lru.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (rc *ResponseCache) timeNow() time.Time {
+	if rc.now != nil {
+		return rc.now()
+	}
+	return time.Now()
+}
+
+// cacheKey returns the key the response to req is cached under.
+func cacheKey(req *http.Request) string {
+	return req.URL.String() + " " + req.Header.Get("Authorization")
+}
+
+// cacheTTL returns how long the response to req is fresh if it's cached.
+func (c *Client) cacheTTL(req *http.Request) (time.Duration, bool) {
+	if c.Cache == nil || req.Method != http.MethodGet {
+		return 0, false
+	}
+	ttl, ok := c.Cache.TTLs[c.endpointGroup(req)]
+	return ttl, ok && ttl > 0
+}
+
+// freshCached returns the response to req if it's cached and fresh.
+func (c *Client) freshCached(req *http.Request) (*http.Response, bool) {
+	if _, ok := c.cacheTTL(req); !ok {
+		return nil, false
+	}
+	entry, cached := c.Cache.get(cacheKey(req))
+	if !cached || !c.Cache.timeNow().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.response(req), true
+}
+
+// sendCached sends the request using the client's Cache. GET responses are
+// served from and stored in the cache while other requests invalidate the
+// entries of the path they mutate.
+func (c *Client) sendCached(req *http.Request) (*http.Response, error) {
+	rc := c.Cache
+	if rc == nil {
+		return c.sendAuthorized(req)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := c.sendAuthorized(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			rc.Invalidate(mutatedPath(req.URL.Path))
+		}
+		return resp, err
+	}
+	ttl, ok := c.cacheTTL(req)
+	if !ok {
+		return c.sendAuthorized(req)
+	}
+
+	entry, cached := rc.get(cacheKey(req))
+	if cached {
+		if rc.timeNow().Before(entry.expires) {
+			return entry.response(req), nil
+		}
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+	}
+
+	resp, err := c.sendAuthorized(req)
+	if err != nil {
+		return resp, err
+	}
+	// the response is cached under the token it was sent with which
+	// differs from the one of the entry if it was refreshed
+	key := cacheKey(req)
+	switch {
+	case cached && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		entry.key = key
+		entry.expires = rc.timeNow().Add(ttl)
+		rc.put(&entry)
+		return entry.response(req), nil
+	case resp.StatusCode != http.StatusOK:
+		return resp, nil
+	case strings.Contains(resp.Header.Get("Cache-Control"), "no-store"):
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	rc.put(&cacheEntry{
+		key:     key,
+		path:    strings.TrimSuffix(req.URL.Path, "/"),
+		status:  resp.StatusCode,
+		header:  resp.Header.Clone(),
+		body:    body,
+		etag:    resp.Header.Get("ETag"),
+		expires: rc.timeNow().Add(ttl),
+	})
+	return resp, nil
+}
+
+// response returns a new response holding the cached one.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// mutatedPath returns the path of the entity a mutating request to the given
+// path affects. That's the entity itself for requests on sub-resources like
+// /posts/3/stars and the whole collection for requests like POST /posts.
+func mutatedPath(path string) string {
+	segments := strings.SplitN(strings.Trim(path, "/"), "/", 3)
+	if len(segments) > 2 {
+		segments = segments[:2]
+	}
+	return "/" + strings.Join(segments, "/")
+}
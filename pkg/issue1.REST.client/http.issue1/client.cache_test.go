@@ -0,0 +1,165 @@
+package issue1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	var gets, revalidations int
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			gets++
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidations++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless","firstName":"Loveless"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless","firstName":"Lovely"}}`))
+		}
+	})
+	defer done()
+
+	now := time.Now()
+	c.Cache = NewResponseCache(2)
+	c.Cache.now = func() time.Time { return now }
+
+	getUser := func() {
+		t.Helper()
+		u, err := c.UserService.GetUser("loveless")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if u.FirstName != "Loveless" {
+			t.Fatalf("FirstName = %q, want %q", u.FirstName, "Loveless")
+		}
+	}
+
+	getUser()
+	getUser()
+	if gets != 1 {
+		t.Errorf("gets = %d, fresh response wasn't served from the cache", gets)
+	}
+
+	now = now.Add(2 * time.Minute)
+	getUser()
+	if gets != 2 || revalidations != 1 {
+		t.Errorf("gets = %d, revalidations = %d, stale response wasn't revalidated", gets, revalidations)
+	}
+	getUser()
+	if gets != 2 {
+		t.Errorf("gets = %d, revalidated response wasn't served from the cache", gets)
+	}
+
+	if _, err := c.UserService.UpdateUser("loveless", &User{FirstName: "Lovely"}, "token"); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if c.Cache.Len() != 0 {
+		t.Errorf("Len() = %d, mutation didn't invalidate the cache", c.Cache.Len())
+	}
+
+	for _, username := range []string{"a", "b", "c"} {
+		_, _ = c.UserService.GetUser(username)
+	}
+	if c.Cache.Len() != 2 {
+		t.Errorf("Len() = %d, want %d", c.Cache.Len(), 2)
+	}
+}
+
+func TestResponseCacheBypassesCircuitBreaker(t *testing.T) {
+	down := false
+	requests := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if down {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"status":"error","message":"database unreachable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless"}}`))
+	})
+	defer done()
+
+	now := time.Now()
+	c.Cache = NewResponseCache(10)
+	c.Cache.TTLs["users"] = time.Hour
+	c.Cache.now = func() time.Time { return now }
+	c.CircuitBreaker = NewCircuitBreaker(2, time.Minute)
+	c.CircuitBreaker.now = func() time.Time { return now }
+
+	if _, err := c.UserService.GetUser("loveless"); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	// hits in between the failures don't reset them
+	down = true
+	for i, username := range []string{"kevin", "loveless", "bilinda"} {
+		_, err := c.UserService.GetUser(username)
+		if username == "loveless" && err != nil {
+			t.Fatalf("attempt %d: cached err = %v", i+1, err)
+		}
+	}
+	if got := c.CircuitBreaker.State("users"); got != CircuitOpen {
+		t.Fatalf("state = %v, want %v", got, CircuitOpen)
+	}
+
+	// cached responses are served while the circuit is open
+	requests = 0
+	if _, err := c.UserService.GetUser("loveless"); err != nil {
+		t.Errorf("open circuit: cached err = %v", err)
+	}
+	if _, err := c.UserService.GetUser("kevin"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("open circuit: err = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	// and don't close it once it's half-open
+	now = now.Add(time.Minute)
+	if _, err := c.UserService.GetUser("loveless"); err != nil {
+		t.Errorf("half-open circuit: cached err = %v", err)
+	}
+	if got := c.CircuitBreaker.State("users"); got != CircuitHalfOpen {
+		t.Errorf("state after a cache hit = %v, want %v", got, CircuitHalfOpen)
+	}
+	if requests != 0 {
+		t.Errorf("%d requests reached the server", requests)
+	}
+}
+
+func TestResponseCacheRefreshedToken(t *testing.T) {
+	gets := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token-auth-refresh":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"token":"new"}}`))
+		case r.Header.Get("Authorization") != "Bearer new":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			gets++
+			_, _ = w.Write([]byte(`{"status":"success","data":[{"id":1,"title":"Only Shallow"}]}`))
+		}
+	})
+	defer done()
+	c.Cache = NewResponseCache(10)
+
+	ts := &testTokenSource{"old"}
+	ctx := WithTokenSource(context.Background(), ts)
+	for i := 0; i < 2; i++ {
+		posts, err := c.FeedService.GetFeedPostsContext(ctx, "loveless", "", PaginateParams{}, ts.Token())
+		if err != nil {
+			t.Fatalf("attempt %d: err = %v", i+1, err)
+		}
+		if len(posts) != 1 {
+			t.Fatalf("attempt %d: %d posts, want 1", i+1, len(posts))
+		}
+	}
+	if gets != 1 {
+		t.Errorf("gets = %d, the response to the refreshed token wasn't cached under it", gets)
+	}
+}
@@ -0,0 +1,422 @@
+package issue1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrCassetteMiss is returned when replaying a cassette that has no unused
+// interaction matching the request.
+var ErrCassetteMiss = errors.New("http.issue1: no recorded interaction matches the request")
+
+// Cassette holds request/response pairs recorded from the REST server so
+// that they can be replayed later without it.
+//
+// Recording a session:
+//
+//	cassette := issue1.NewCassette("testdata/session.json")
+//	c.Use(cassette.Record())
+//	...
+//	err := cassette.Save()
+//
+// Replaying it:
+//
+//	cassette, err := issue1.LoadCassette("testdata/session.json")
+//	...
+//	c.Use(cassette.Replay())
+//
+// Requests are matched on their method, path, query and body. Each recorded
+// interaction is replayed once and in the order it was recorded so repeated
+// requests get the responses they got when recording. Credentials, that is
+// the values of the password and token fields of JSON bodies and the headers
+// RedactHeader redacts, are redacted before being recorded. Request bodies
+// larger than MaxRecordedBodySize, like the uploaded images, are recorded
+// as their size and SHA-256 hash instead and are never held in memory.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+
+	path   string
+	mu     sync.Mutex
+	played map[*Interaction]bool
+}
+
+// Interaction is a recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// MaxRecordedBodySize is the size in bytes of the largest request body
+// recorded on a Cassette as is.
+const MaxRecordedBodySize = 64 << 10
+
+// RecordedRequest is a request recorded on a Cassette.
+type RecordedRequest struct {
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	Query      string      `json:"query,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"bodyBase64,omitempty"`
+	// BodySize and BodySHA256 stand for the bodies larger than
+	// MaxRecordedBodySize. The boundaries of multipart bodies are
+	// normalized before they're hashed.
+	BodySize   int64  `json:"bodySize,omitempty"`
+	BodySHA256 string `json:"bodySHA256,omitempty"`
+}
+
+// RecordedResponse is a response recorded on a Cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"bodyBase64,omitempty"`
+}
+
+// NewCassette returns an empty Cassette that's saved to the file at the
+// given path by Save. The path may be empty in which case it's only kept in
+// memory.
+func NewCassette(path string) *Cassette {
+	return &Cassette{
+		Interactions: make([]*Interaction, 0),
+		path:         path,
+	}
+}
+
+// LoadCassette reads the Cassette saved at the given path.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := NewCassette(path)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("http.issue1: unable to parse cassette %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the Cassette to the file at its path.
+func (c *Cassette) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c, "", "\t")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.path, b, 0644); err != nil {
+		return fmt.Errorf("http.issue1: unable to save cassette %s: %w", c.path, err)
+	}
+	return nil
+}
+
+// Record returns Middleware that records every request sent through it and
+// the response it got on the Cassette. Requests that fail without a response
+// aren't recorded. The interactions are kept in memory until Save is
+// called.
+func (c *Cassette) Record() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// the body is summarized while it's sent
+			req, reqBody := summarizeRequestBody(req)
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			respBody, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+			interaction := &Interaction{
+				Request: RecordedRequest{
+					Method: req.Method,
+					Path:   req.URL.Path,
+					Query:  req.URL.Query().Encode(),
+					Header: RedactHeader(req.Header),
+				},
+				Response: RecordedResponse{
+					StatusCode: resp.StatusCode,
+					Header:     RedactHeader(resp.Header),
+				},
+			}
+			if body, size, sum := reqBody.result(); sum == "" {
+				interaction.Request.Body, interaction.Request.BodyBase64 = encodeBody(redactBody(body))
+			} else {
+				interaction.Request.BodySize, interaction.Request.BodySHA256 = size, sum
+			}
+			interaction.Response.Body, interaction.Response.BodyBase64 = encodeBody(redactBody(respBody))
+
+			c.mu.Lock()
+			c.Interactions = append(c.Interactions, interaction)
+			c.mu.Unlock()
+			return resp, nil
+		})
+	}
+}
+
+// Replay returns Middleware that answers requests with the responses
+// recorded on the Cassette instead of sending them. Requests without a
+// matching interaction fail with ErrCassetteMiss.
+func (c *Cassette) Replay() Middleware {
+	return func(http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req, reqBody := summarizeRequestBody(req)
+			if req.Body != nil {
+				_, err := io.Copy(ioutil.Discard, req.Body)
+				req.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+			}
+			body, size, sum := reqBody.result()
+			if sum == "" {
+				body = redactBody(body)
+			} else {
+				body = largeBodyKey(size, sum)
+			}
+			key := interactionKey(req.Method, req.URL.Path, req.URL.Query().Encode(),
+				req.Header.Get("Content-Type"), body)
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.played == nil {
+				c.played = make(map[*Interaction]bool)
+			}
+			for _, interaction := range c.Interactions {
+				if c.played[interaction] || interaction.Request.key() != key {
+					continue
+				}
+				c.played[interaction] = true
+				return interaction.Response.response(req)
+			}
+			return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, req.Method, req.URL)
+		})
+	}
+}
+
+// Rewind marks all the interactions of the Cassette as not replayed.
+func (c *Cassette) Rewind() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.played = nil
+}
+
+func (rr *RecordedRequest) key() string {
+	body, _ := decodeBody(rr.Body, rr.BodyBase64)
+	if rr.BodySHA256 != "" {
+		body = largeBodyKey(rr.BodySize, rr.BodySHA256)
+	}
+	return interactionKey(rr.Method, rr.Path, rr.Query, rr.Header.Get("Content-Type"), body)
+}
+
+func (rr *RecordedResponse) response(req *http.Request) (*http.Response, error) {
+	body, err := decodeBody(rr.Body, rr.BodyBase64)
+	if err != nil {
+		return nil, fmt.Errorf("http.issue1: unable to decode recorded response body: %w", err)
+	}
+	header := rr.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// interactionKey identifies the request described by the given parts. The
+// boundaries of multipart bodies are normalized since they're random.
+func interactionKey(method, path, query, contentType string, body []byte) string {
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil &&
+		strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), []byte("BOUNDARY"))
+	}
+	return method + " " + path + "?" + query + "\n" + string(body)
+}
+
+// largeBodyKey stands for a body larger than MaxRecordedBodySize in the
+// key of its request.
+func largeBodyKey(size int64, sum string) []byte {
+	return []byte(fmt.Sprintf("[%d bytes, sha256 %s]", size, sum))
+}
+
+// summarizeRequestBody returns a copy of the request whose body is
+// summarized by the returned bodySummary as it's read. RoundTrippers mustn't
+// modify the request they're given.
+func summarizeRequestBody(req *http.Request) (*http.Request, *bodySummary) {
+	summary := newBodySummary(req.Header.Get("Content-Type"))
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, summary
+	}
+	req = req.Clone(req.Context())
+	req.Body = summary.tee(req.Body)
+	if getBody := req.GetBody; getBody != nil {
+		// the body is read again from the start if the request is resent
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			summary.reset()
+			return summary.tee(body), nil
+		}
+	}
+	return req, summary
+}
+
+// bodySummary is written the body of a request as it's read. It keeps the
+// body if it isn't larger than MaxRecordedBodySize and its size and hash
+// otherwise. The boundary of multipart bodies is replaced before hashing.
+type bodySummary struct {
+	boundary []byte
+
+	mu   sync.Mutex
+	body []byte
+	size int64
+	hash hash.Hash
+	// tail holds the end of the body that's not hashed yet as it may be
+	// the start of a boundary
+	tail []byte
+}
+
+func newBodySummary(contentType string) *bodySummary {
+	s := &bodySummary{hash: sha256.New()}
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil &&
+		strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		s.boundary = []byte(params["boundary"])
+	}
+	return s
+}
+
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+func (s *bodySummary) tee(body io.ReadCloser) io.ReadCloser {
+	return teeBody{io.TeeReader(body, s), body}
+}
+
+func (s *bodySummary) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.size, s.tail = nil, 0, nil
+	s.hash.Reset()
+}
+
+func (s *bodySummary) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size += int64(len(p))
+	if len(s.body) <= MaxRecordedBodySize {
+		n := MaxRecordedBodySize + 1 - len(s.body)
+		if n > len(p) {
+			n = len(p)
+		}
+		s.body = append(s.body, p[:n]...)
+	}
+	if s.boundary == nil {
+		s.hash.Write(p)
+		return len(p), nil
+	}
+	data := bytes.ReplaceAll(append(s.tail, p...), s.boundary, []byte("BOUNDARY"))
+	keep := len(s.boundary) - 1
+	if keep > len(data) {
+		keep = len(data)
+	}
+	s.hash.Write(data[:len(data)-keep])
+	s.tail = data[len(data)-keep:]
+	return len(p), nil
+}
+
+// result returns the body read so far or, if it's larger than
+// MaxRecordedBodySize, nil along with its size and hex encoded hash. It's
+// called once the body has been read.
+func (s *bodySummary) result() (body []byte, size int64, sum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size <= MaxRecordedBodySize {
+		return s.body, s.size, ""
+	}
+	s.hash.Write(s.tail)
+	s.tail = nil
+	return nil, s.size, hex.EncodeToString(s.hash.Sum(nil))
+}
+
+// redactedFields are the JSON object fields redactBody redacts.
+var redactedFields = map[string]bool{"password": true, "token": true}
+
+// redactBody redacts the credentials from JSON bodies. The JSON is
+// re-encoded in a canonical form so bodies with the same content are equal.
+// Other bodies are returned as is.
+func redactBody(body []byte) []byte {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	redacted, err := json.Marshal(redactJSON(v))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if _, isString := value.(string); isString && redactedFields[key] {
+				v[key] = "[REDACTED]"
+			} else {
+				v[key] = redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+	return v
+}
+
+// encodeBody returns the body as a string if it's valid UTF-8 or base64
+// encoded otherwise.
+func encodeBody(body []byte) (text, b64 string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(body)
+}
+
+func decodeBody(text, b64 string) ([]byte, error) {
+	if b64 != "" {
+		return base64.StdEncoding.DecodeString(b64)
+	}
+	return []byte(text), nil
+}
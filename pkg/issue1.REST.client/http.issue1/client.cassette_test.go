@@ -0,0 +1,149 @@
+package issue1_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// commentSession is the session the cassette is recorded from and replayed
+// with. It's what the postPostComments handler goes through.
+func commentSession(t *testing.T, i1 *issue1.Client, postID uint) []*issue1.Comment {
+	token, err := i1.GetAuthToken(loveless.Username, loveless.Password)
+	if err != nil {
+		t.Fatalf("GetAuthToken() returned error %s", err)
+	}
+	if _, err := i1.CommentService.AddComment(postID, &issue1.Comment{Content: "soft as a cloud"}, token); err != nil {
+		t.Fatalf("AddComment() returned error %s", err)
+	}
+	image := bytes.NewReader([]byte("\xff\xd8\xff\xe0 not quite a jpeg"))
+	if _, err := i1.UserService.AddPicture(loveless.Username, image, "lovelessness.jpg", token); err != nil {
+		t.Fatalf("AddPicture() returned error %s", err)
+	}
+	comments, err := i1.CommentService.GetComments(postID, issue1.SortCommentsByCreationTime, issue1.PaginateParams{})
+	if err != nil {
+		t.Fatalf("GetComments() returned error %s", err)
+	}
+	return comments
+}
+
+func TestCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	srv, i1, token := newFakeServer(t)
+	p := srv.AddPost(issue1.Post{Title: "Only Shallow"})
+	recording := issue1.NewCassette(path)
+	i1.Use(recording.Record())
+	recorded := commentSession(t, i1, p.ID)
+	srv.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cassette was written before being saved: %v", err)
+	}
+	if err := recording.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{`"password":"` + loveless.Password, token} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette contains the secret %q", secret)
+		}
+	}
+	if !strings.Contains(string(b), "Bearer [REDACTED]") {
+		t.Errorf("cassette doesn't contain the redacted Authorization header")
+	}
+
+	cassette, err := issue1.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the server is closed so requests reaching it fail
+	i1 = srv.Client()
+	i1.Use(cassette.Replay())
+	replayed := commentSession(t, i1, p.ID)
+	if len(replayed) != 1 || replayed[0].Content != recorded[0].Content {
+		t.Errorf("replayed comments %+v, wanted %+v", replayed, recorded)
+	}
+
+	_, err = i1.CommentService.GetComments(p.ID, issue1.SortCommentsByCreationTime, issue1.PaginateParams{})
+	if !errors.Is(err, issue1.ErrCassetteMiss) {
+		t.Errorf("replaying a request once too many returned error %v, wanted %v", err, issue1.ErrCassetteMiss)
+	}
+	cassette.Rewind()
+	if replayed := commentSession(t, i1, p.ID); len(replayed) != 1 {
+		t.Errorf("rewound cassette replayed %+v", replayed)
+	}
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
+
+func TestCassetteLargeUpload(t *testing.T) {
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
+	picture := append([]byte("\xff\xd8\xff\xe0"), bytes.Repeat([]byte("shoegaze"), issue1.MaxRecordedBodySize/4)...)
+
+	var image *countingReader
+	recording := issue1.NewCassette("")
+	i1.Use(recording.Record(), func(next http.RoundTripper) http.RoundTripper {
+		return issue1.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// only the 512 bytes its type is detected from are read
+			if n := atomic.LoadInt64(&image.n); n > 512 {
+				t.Errorf("%d bytes of the image were read before it was sent", n)
+			}
+			return next.RoundTrip(req)
+		})
+	})
+	image = &countingReader{r: bytes.NewReader(picture)}
+	if _, err := i1.UserService.AddPicture(loveless.Username, image, "loveless.jpg", token); err != nil {
+		t.Fatalf("AddPicture() returned error %s", err)
+	}
+	if len(recording.Interactions) != 1 {
+		t.Fatalf("%d interactions were recorded, want 1", len(recording.Interactions))
+	}
+	recorded := recording.Interactions[0].Request
+	if recorded.Body != "" || recorded.BodyBase64 != "" {
+		t.Error("the body of the upload was recorded")
+	}
+	if recorded.BodySize <= int64(len(picture)) || recorded.BodySHA256 == "" {
+		t.Errorf("BodySize = %d, BodySHA256 = %q, want the size and hash of the upload", recorded.BodySize, recorded.BodySHA256)
+	}
+
+	// the multipart boundary differs from the recorded one
+	i1 = srv.Client()
+	i1.Use(recording.Replay())
+	if _, err := i1.UserService.AddPicture(loveless.Username, bytes.NewReader(picture), "loveless.jpg", token); err != nil {
+		t.Errorf("replaying the upload returned error %s", err)
+	}
+	picture[len(picture)-1] = '!'
+	recording.Rewind()
+	if _, err := i1.UserService.AddPicture(loveless.Username, bytes.NewReader(picture), "loveless.jpg", token); !errors.Is(err, issue1.ErrCassetteMiss) {
+		t.Errorf("replaying another upload returned error %v, wanted %v", err, issue1.ErrCassetteMiss)
+	}
+}
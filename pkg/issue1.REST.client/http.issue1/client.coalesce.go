@@ -0,0 +1,97 @@
+package issue1
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Coalescer dedupes identical GET requests that are in flight at the same
+// time. Only the first one is sent to the REST server and the others wait
+// for it and share its decoded response. Requests are identical if they
+// have the same URL and Authorization header.
+type Coalescer struct {
+	// accessed atomically, first in the struct for 64-bit alignment
+	sent      uint64
+	collapsed uint64
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done       chan struct{}
+	js         *jSendResponse
+	statusCode int
+	err        error
+	// abandoned is set if the request failed because its context was done
+	abandoned bool
+}
+
+// CoalescerStats holds the number of requests that went through a Coalescer.
+type CoalescerStats struct {
+	// Sent is the number of requests that were sent to the REST server.
+	Sent uint64
+	// Collapsed is the number of requests that weren't sent and used the
+	// response of an identical request in flight instead.
+	Collapsed uint64
+}
+
+// NewCoalescer returns a new Coalescer.
+func NewCoalescer() *Coalescer {
+	return &Coalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// Stats returns the number of requests sent and collapsed so far.
+func (co *Coalescer) Stats() CoalescerStats {
+	return CoalescerStats{
+		Sent:      atomic.LoadUint64(&co.sent),
+		Collapsed: atomic.LoadUint64(&co.collapsed),
+	}
+}
+
+// do calls fn for the request unless an identical one is in flight in which
+// case it waits for and returns its results. The returned jSendResponse is
+// shared and must not be modified.
+func (co *Coalescer) do(req *http.Request, fn func(*http.Request) (*jSendResponse, int, error)) (*jSendResponse, int, error) {
+	if req.Method != http.MethodGet {
+		return fn(req)
+	}
+	key := req.URL.String() + " " + req.Header.Get("Authorization")
+
+	co.mu.Lock()
+	if co.calls == nil {
+		co.calls = make(map[string]*coalescedCall)
+	}
+	if call, ok := co.calls[key]; ok {
+		co.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-req.Context().Done():
+			return nil, -1, req.Context().Err()
+		}
+		if call.abandoned && req.Context().Err() == nil {
+			// the request we waited on was abandoned by its caller,
+			// ours wasn't
+			return co.do(req, fn)
+		}
+		atomic.AddUint64(&co.collapsed, 1)
+		return call.js, call.statusCode, call.err
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	co.calls[key] = call
+	co.mu.Unlock()
+
+	atomic.AddUint64(&co.sent, 1)
+	call.js, call.statusCode, call.err = fn(req)
+	if call.err != nil && req.Context().Err() != nil {
+		call.abandoned = true
+	}
+
+	co.mu.Lock()
+	delete(co.calls, key)
+	co.mu.Unlock()
+	close(call.done)
+
+	return call.js, call.statusCode, call.err
+}
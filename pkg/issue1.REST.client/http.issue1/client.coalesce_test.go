@@ -0,0 +1,60 @@
+package issue1
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalescer(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	hits := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		entered <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless"}}`))
+	})
+	defer done()
+	c.Coalescer = NewCoalescer()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	users := make([]*User, callers)
+	errs := make([]error, callers)
+	call := func(i int) {
+		defer wg.Done()
+		users[i], errs[i] = c.UserService.GetUser("loveless")
+	}
+	wg.Add(callers)
+	go call(0)
+	<-entered
+	for i := 1; i < callers; i++ {
+		go call(i)
+	}
+	// give the other callers time to join the request in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range users {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: err = %v", i, errs[i])
+		}
+		if users[i].Username != "loveless" {
+			t.Errorf("caller %d: Username = %q", i, users[i].Username)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("hits = %d, want 1", hits)
+	}
+	want := CoalescerStats{Sent: 1, Collapsed: callers - 1}
+	if got := c.Coalescer.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
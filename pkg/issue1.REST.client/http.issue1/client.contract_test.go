@@ -0,0 +1,456 @@
+package issue1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// contract is the description of the REST API the client consumes found in
+// endpoints.json. The contract tests check the client against it so that
+// changes to the issue-1-REST API are caught by updating the file.
+type contract struct {
+	// Schemas maps the names of the objects exchanged with the server to
+	// their fields and the types of the fields.
+	Schemas   map[string]map[string]string `json:"schemas"`
+	Endpoints []contractEndpoint           `json:"endpoints"`
+}
+
+type contractEndpoint struct {
+	Name     string            `json:"name"`
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Auth     bool              `json:"auth"`
+	Query    []string          `json:"query"`
+	Body     string            `json:"body"`
+	Data     string            `json:"data"`
+	Failures []contractFailure `json:"failures"`
+}
+
+type contractFailure struct {
+	Status int    `json:"status"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+func loadContract(t *testing.T) *contract {
+	b, err := ioutil.ReadFile("endpoints.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := new(contract)
+	if err := json.Unmarshal(b, c); err != nil {
+		t.Fatalf("unable to parse endpoints.json: %v", err)
+	}
+	return c
+}
+
+// contractTypes maps the schemas of the contract to the types the client
+// decodes them into.
+var contractTypes = map[string]reflect.Type{
+	"User":          reflect.TypeOf(User{}),
+	"Channel":       reflect.TypeOf(Channel{}),
+	"Comment":       reflect.TypeOf(Comment{}),
+	"Feed":          reflect.TypeOf(Feed{}),
+	"Post":          reflect.TypeOf(Post{}),
+	"Star":          reflect.TypeOf(Star{}),
+	"Release":       reflect.TypeOf(Release{}),
+	"Metadata":      reflect.TypeOf(Metadata{}),
+	"Other":         reflect.TypeOf(Other{}),
+	"SearchResults": reflect.TypeOf(SearchResults{}),
+}
+
+var contractErrors = map[string]error{
+	"ErrAccessDenied":          ErrAccessDenied,
+	"ErrAdminAlreadyExists":    ErrAdminAlreadyExists,
+	"ErrAdminNotFound":         ErrAdminNotFound,
+	"ErrChannelNotFound":       ErrChannelNotFound,
+	"ErrCommentNotFound":       ErrCommentNotFound,
+	"ErrCredentialsUnaccepted": ErrCredentialsUnaccepted,
+	"ErrEmailIsOccupied":       ErrEmailIsOccupied,
+	"ErrForbiddenAccess":       ErrForbiddenAccess,
+	"ErrInvalidData":           ErrInvalidData,
+	"ErrPostAlreadyStickied":   ErrPostAlreadyStickied,
+	"ErrPostNotFound":          ErrPostNotFound,
+	"ErrReleaseAlreadyExists":  ErrReleaseAlreadyExists,
+	"ErrReleaseNotFound":       ErrReleaseNotFound,
+	"ErrStarNotFound":          ErrStarNotFound,
+	"ErrStickiedPostFull":      ErrStickiedPostFull,
+	"ErrStickiedPostNotFound":  ErrStickiedPostNotFound,
+	"ErrUnacceptedImageType":   ErrUnacceptedImageType,
+	"ErrUserNameOccupied":      ErrUserNameOccupied,
+	"ErrUserNotFound":          ErrUserNotFound,
+}
+
+// jpeg starts like a JPEG image does.
+const jpeg = "\xff\xd8\xff\xe0\x00\x10JFIF"
+
+// contractCalls calls the client method consuming each endpoint.
+var contractCalls = map[string]func(c *Client) error{
+	"AuthService.GetAuthToken":     func(c *Client) error { _, err := c.GetAuthToken("loveless", "password"); return err },
+	"AuthService.RefreshAuthToken": func(c *Client) error { _, err := c.RefreshAuthToken("token"); return err },
+	"AuthService.Logout":           func(c *Client) error { return c.Logout("token") },
+
+	"UserService.AddUser": func(c *Client) error {
+		_, err := c.UserService.AddUser(&User{Username: "loveless", Email: "kevin@mbv.ie", FirstName: "Kevin", Password: "password"})
+		return err
+	},
+	"UserService.GetUser":           func(c *Client) error { _, err := c.UserService.GetUser("loveless"); return err },
+	"UserService.GetUserAuthorized": func(c *Client) error { _, err := c.UserService.GetUserAuthorized("loveless", "token"); return err },
+	"UserService.SearchUsers": func(c *Client) error {
+		_, err := c.UserService.SearchUsers("loveless", SortUsersByUsername, PaginateParams{Limit: 5})
+		return err
+	},
+	"UserService.UpdateUser": func(c *Client) error {
+		_, err := c.UserService.UpdateUser("loveless", &User{Bio: "only shallow"}, "token")
+		return err
+	},
+	"UserService.DeleteUser":       func(c *Client) error { return c.UserService.DeleteUser("loveless", "token") },
+	"UserService.GetUserBookmarks": func(c *Client) error { _, err := c.UserService.GetUserBookmarks("loveless", "token"); return err },
+	"UserService.BookmarkPost":     func(c *Client) error { return c.UserService.BookmarkPost("loveless", 3, "token") },
+	"UserService.DeleteBookmark":   func(c *Client) error { return c.UserService.DeleteBookmark("loveless", 3, "token") },
+	"UserService.AddPicture": func(c *Client) error {
+		_, err := c.UserService.AddPicture("loveless", strings.NewReader(jpeg), "front.jpg", "token")
+		return err
+	},
+	"UserService.RemovePicture": func(c *Client) error { return c.UserService.RemovePicture("loveless", "token") },
+
+	"FeedService.GetFeedSorting": func(c *Client) error { _, err := c.FeedService.GetFeedSorting("loveless", "token"); return err },
+	"FeedService.GetFeedPosts": func(c *Client) error {
+		_, err := c.FeedService.GetFeedPosts("loveless", SortHot, PaginateParams{Limit: 5}, "token")
+		return err
+	},
+	"FeedService.GetFeedSubscriptions": func(c *Client) error {
+		_, err := c.FeedService.GetFeedSubscriptions("loveless", "token", SortBySubscriptionTime, SortDescending)
+		return err
+	},
+	"FeedService.SubscribeToChannel":     func(c *Client) error { return c.FeedService.SubscribeToChannel("loveless", "mbv", "token") },
+	"FeedService.SetFeedSorting":         func(c *Client) error { return c.FeedService.SetFeedSorting(SortTop, "loveless", "token") },
+	"FeedService.UnsubscribeFromChannel": func(c *Client) error { return c.FeedService.UnsubscribeFromChannel("loveless", "mbv", "token") },
+
+	"ChannelService.AddChannel": func(c *Client) error {
+		_, err := c.ChannelService.AddChannel(&Channel{ChannelUsername: "mbv"}, "token")
+		return err
+	},
+	"ChannelService.GetChannel":           func(c *Client) error { _, err := c.ChannelService.GetChannel("mbv"); return err },
+	"ChannelService.GetChannelAuthorized": func(c *Client) error { _, err := c.ChannelService.GetChannelAuthorized("mbv", "token"); return err },
+	"ChannelService.SearchChannels": func(c *Client) error {
+		_, err := c.ChannelService.SearchChannels("mbv", SortChannelsByUsername, PaginateParams{Limit: 5})
+		return err
+	},
+	"ChannelService.UpdateChannel": func(c *Client) error {
+		_, err := c.ChannelService.UpdateChannel("mbv", &Channel{Name: "My Bloody Valentine"}, "token")
+		return err
+	},
+	"ChannelService.DeleteChannel": func(c *Client) error { return c.ChannelService.DeleteChannel("mbv", "token") },
+	"ChannelService.AddPicture": func(c *Client) error {
+		_, err := c.ChannelService.AddPicture("mbv", strings.NewReader(jpeg), "front.jpg", "token")
+		return err
+	},
+	"ChannelService.RemovePicture": func(c *Client) error { return c.ChannelService.RemovePicture("mbv", "token") },
+	"ChannelService.AddAdmin":      func(c *Client) error { return c.ChannelService.AddAdmin("mbv", "loveless", "token") },
+	"ChannelService.DeleteAdmin":   func(c *Client) error { return c.ChannelService.DeleteAdmin("mbv", "loveless", "token") },
+	"ChannelService.ChangeOwner":   func(c *Client) error { return c.ChannelService.ChangeOwner("mbv", "loveless", "token") },
+	"ChannelService.DeleteReleaseFromCatalog": func(c *Client) error {
+		return c.ChannelService.DeleteReleaseFromCatalog("mbv", 7, "token")
+	},
+	"ChannelService.DeleteReleaseFromOfficialCatalog": func(c *Client) error {
+		return c.ChannelService.DeleteReleaseFromOfficialCatalog("mbv", 7, "token")
+	},
+	"ChannelService.AddReleaseToOfficialCatalog": func(c *Client) error {
+		return c.ChannelService.AddReleaseToOfficialCatalog("mbv", 7, 3, "token")
+	},
+	"ChannelService.StickyPost":         func(c *Client) error { return c.ChannelService.StickyPost("mbv", 3, "token") },
+	"ChannelService.DeleteStickiedPost": func(c *Client) error { return c.ChannelService.DeleteStickiedPost("mbv", 3, "token") },
+	"ChannelService.GetChannelPosts":    func(c *Client) error { _, err := c.ChannelService.GetChannelPosts("mbv"); return err },
+	"ChannelService.GetCatalog":         func(c *Client) error { _, err := c.ChannelService.GetCatalog("mbv", "token"); return err },
+	"ChannelService.GetOfficialCatalog": func(c *Client) error { _, err := c.ChannelService.GetOfficialCatalog("mbv", "token"); return err },
+	"ChannelService.GetChannelPost":     func(c *Client) error { _, err := c.ChannelService.GetChannelPost("mbv", 3); return err },
+	"ChannelService.GetReleaseInCatalog": func(c *Client) error {
+		_, err := c.ChannelService.GetReleaseInCatalog("mbv", 7, "token")
+		return err
+	},
+	"ChannelService.GetReleaseInOfficialCatalog": func(c *Client) error {
+		_, err := c.ChannelService.GetReleaseInOfficialCatalog("mbv", 7)
+		return err
+	},
+	"ChannelService.GetStickiedPosts": func(c *Client) error { _, err := c.ChannelService.GetStickiedPosts("mbv"); return err },
+	"ChannelService.GetAdmins":        func(c *Client) error { _, err := c.ChannelService.GetAdmins("mbv", "token"); return err },
+	"ChannelService.GetOwner":         func(c *Client) error { _, err := c.ChannelService.GetOwner("mbv", "token"); return err },
+
+	"PostService.SearchPosts": func(c *Client) error {
+		_, err := c.PostService.SearchPosts("shallow", SortPostsByTitle, PaginateParams{Limit: 5})
+		return err
+	},
+	"PostService.GetPost": func(c *Client) error { _, err := c.PostService.GetPost(3); return err },
+	"PostService.AddPost": func(c *Client) error {
+		_, err := c.PostService.AddPost(&Post{OriginChannel: "mbv", Title: "Only Shallow"}, "token")
+		return err
+	},
+	"PostService.DeletePost": func(c *Client) error { return c.PostService.DeletePost(3, "token") },
+	"PostService.UpdatePost": func(c *Client) error {
+		_, err := c.PostService.UpdatePost(3, &Post{Title: "Soon"}, "token")
+		return err
+	},
+	"PostService.GetPostComments": func(c *Client) error { _, err := c.PostService.GetPostComments(3); return err },
+	"PostService.GetPostReleases": func(c *Client) error { _, err := c.PostService.GetPostReleases(3); return err },
+	"PostService.GetPostStars":    func(c *Client) error { _, err := c.PostService.GetPostStars(3); return err },
+	"PostService.GetPostStar":     func(c *Client) error { _, err := c.PostService.GetPostStar(3, "loveless"); return err },
+	"PostService.UpdatePostStar": func(c *Client) error {
+		_, err := c.PostService.UpdatePostStar(3, &Star{NumOfStars: 4}, "token")
+		return err
+	},
+
+	"ReleaseService.GetRelease":           func(c *Client) error { _, err := c.ReleaseService.GetRelease(7); return err },
+	"ReleaseService.GetReleaseAuthorized": func(c *Client) error { _, err := c.ReleaseService.GetReleaseAuthorized(7, "token"); return err },
+	"ReleaseService.AddTextRelease": func(c *Client) error {
+		_, err := c.ReleaseService.AddTextRelease(&Release{OwnerChannel: "mbv", Content: "Loveless"}, "token")
+		return err
+	},
+	"ReleaseService.AddImageRelease": func(c *Client) error {
+		_, err := c.ReleaseService.AddImageRelease(&Release{OwnerChannel: "mbv"}, strings.NewReader(jpeg), "front.jpg", "token")
+		return err
+	},
+	"ReleaseService.UpdateRelease": func(c *Client) error {
+		_, err := c.ReleaseService.UpdateRelease(7, &Release{Content: "Isn't Anything"}, Text, "token")
+		return err
+	},
+	"ReleaseService.UpdateImageRelease": func(c *Client) error {
+		_, err := c.ReleaseService.UpdateImageRelease(7, &Release{}, strings.NewReader(jpeg), "back.jpg", "token")
+		return err
+	},
+	"ReleaseService.DeleteRelease": func(c *Client) error { return c.ReleaseService.DeleteRelease(7, "token") },
+	"ReleaseService.SearchReleases": func(c *Client) error {
+		_, err := c.ReleaseService.SearchReleases("loveless", SortReleaseByCreationTime, PaginateParams{Limit: 5})
+		return err
+	},
+
+	"CommentService.AddComment": func(c *Client) error {
+		_, err := c.CommentService.AddComment(3, &Comment{Content: "soft as a cloud"}, "token")
+		return err
+	},
+	"CommentService.AddReply": func(c *Client) error {
+		_, err := c.CommentService.AddReply(11, 3, &Comment{Content: "sharp as a knife"}, "token")
+		return err
+	},
+	"CommentService.GetComment": func(c *Client) error { _, err := c.CommentService.GetComment(11, 3); return err },
+	"CommentService.GetComments": func(c *Client) error {
+		_, err := c.CommentService.GetComments(3, SortCommentsByCreationTime, PaginateParams{Limit: 5})
+		return err
+	},
+	"CommentService.GetReplies": func(c *Client) error {
+		_, err := c.CommentService.GetReplies(11, 3, SortCommentsByCreationTime, PaginateParams{Limit: 5})
+		return err
+	},
+	"CommentService.UpdateComment": func(c *Client) error {
+		_, err := c.CommentService.UpdateComment(11, 3, &Comment{Content: "soft as a cloud"}, "token")
+		return err
+	},
+	"CommentService.DeleteComment": func(c *Client) error { return c.CommentService.DeleteComment(11, 3, "token") },
+
+	"SearchService.Search": func(c *Client) error {
+		_, err := c.SearchService.Search("loveless", SortByRank, PaginateParams{Limit: 5})
+		return err
+	},
+}
+
+// TestContractSchemas checks that the types the client decodes responses
+// into have the fields described by the contract.
+func TestContractSchemas(t *testing.T) {
+	c := loadContract(t)
+	for name, typ := range contractTypes {
+		schema, ok := c.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s isn't described", name)
+			continue
+		}
+		fields := jsonFields(typ)
+		for field, want := range schema {
+			got, ok := fields[field]
+			switch {
+			case !ok:
+				t.Errorf("%s: field %q is missing from %s", name, field, typ)
+			case got != want:
+				t.Errorf("%s: field %q is a %s, want %s", name, field, got, want)
+			}
+		}
+		for field := range fields {
+			if _, ok := schema[field]; !ok {
+				t.Errorf("%s: field %q of %s isn't described", name, field, typ)
+			}
+		}
+	}
+}
+
+// jsonFields returns the names of the JSON fields of the struct type along
+// with their contract types.
+func jsonFields(typ reflect.Type) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			if f.Anonymous {
+				for k, v := range jsonFields(f.Type) {
+					fields[k] = v
+				}
+				continue
+			}
+			name = f.Name
+		}
+		fields[name] = contractType(f.Type)
+	}
+	return fields
+}
+
+func contractType(typ reflect.Type) string {
+	if typ == reflect.TypeOf(time.Time{}) {
+		return "time"
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return contractType(typ.Elem())
+	case reflect.Slice:
+		return "[]" + contractType(typ.Elem())
+	case reflect.Map:
+		return "map[" + contractType(typ.Key()) + "]" + contractType(typ.Elem())
+	case reflect.Struct:
+		return typ.Name()
+	default:
+		return typ.Kind().String()
+	}
+}
+
+// example returns a JSON value of the given contract type.
+func (c *contract) example(typ string) interface{} {
+	switch {
+	case typ == "":
+		return nil
+	case typ == "string":
+		return "loveless"
+	case typ == "uint", typ == "int":
+		return 3
+	case typ == "time":
+		return "2008-05-05T00:00:00Z"
+	case strings.HasPrefix(typ, "[]"):
+		return []interface{}{c.example(typ[2:])}
+	case strings.HasPrefix(typ, "map["):
+		end := strings.Index(typ, "]")
+		key := fmt.Sprint(c.example(typ[4:end]))
+		return map[string]interface{}{key: c.example(typ[end+1:])}
+	}
+	object := make(map[string]interface{})
+	for field, fieldType := range c.Schemas[typ] {
+		object[field] = c.example(fieldType)
+	}
+	return object
+}
+
+// matchPath reports whether the path matches the pattern of the endpoint.
+func matchPath(pattern, path string) bool {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if !strings.HasPrefix(want[i], ":") && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestContractEndpoints serves the responses described by the contract to
+// the client methods consuming the endpoints and checks that they're
+// decoded and the failures are mapped to the described errors.
+func TestContractEndpoints(t *testing.T) {
+	c := loadContract(t)
+	described := make(map[string]bool)
+	for _, ep := range c.Endpoints {
+		ep := ep
+		described[ep.Name] = true
+		call, ok := contractCalls[ep.Name]
+		if !ok {
+			t.Errorf("%s: no client method consumes the endpoint", ep.Name)
+			continue
+		}
+		t.Run(ep.Name, func(t *testing.T) {
+			var status int
+			var body interface{}
+			client, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != ep.Method || !matchPath(ep.Path, r.URL.Path) {
+					t.Errorf("request %s %s, want %s %s", r.Method, r.URL.Path, ep.Method, ep.Path)
+				}
+				if ep.Auth && r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("request isn't authorized")
+				}
+				for key := range r.URL.Query() {
+					if !contains(ep.Query, key) {
+						t.Errorf("undescribed query parameter %q", key)
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				_ = json.NewEncoder(w).Encode(body)
+			})
+			defer done()
+			client.Logger = nil
+
+			status = http.StatusOK
+			body = map[string]interface{}{"status": "success", "data": c.example(ep.Data)}
+			if err := call(client); err != nil {
+				t.Errorf("success response: %v", err)
+			}
+
+			for _, f := range ep.Failures {
+				want, ok := contractErrors[f.Error]
+				if !ok {
+					t.Errorf("unknown error %s", f.Error)
+					continue
+				}
+				status = f.Status
+				if status == anyStatus {
+					// any fail response matches, e.g. a 404
+					status = http.StatusNotFound
+				}
+				body = map[string]interface{}{
+					"status": "fail",
+					"data":   map[string]string{"errorReason": f.Reason, "errorMessage": "contract"},
+				}
+				if err := call(client); !errors.Is(err, want) {
+					t.Errorf("%d %q fail response: err = %v, want %v", f.Status, f.Reason, err, want)
+				}
+			}
+		})
+	}
+	var undescribed []string
+	for name := range contractCalls {
+		if !described[name] {
+			undescribed = append(undescribed, name)
+		}
+	}
+	sort.Strings(undescribed)
+	for _, name := range undescribed {
+		t.Errorf("%s isn't described in endpoints.json", name)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
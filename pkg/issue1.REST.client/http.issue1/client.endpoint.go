@@ -0,0 +1,165 @@
+package issue1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+//go:generate go run ./internal/issue1gen -spec endpoints.json
+
+// failure maps the jSend fail responses of an endpoint with the given
+// status code and errorReason to the error they're returned as. An empty
+// reason matches any reason and the anyStatus status matches any fail
+// response, but not the 401s and 403s rejected before a jSend body is read.
+type failure struct {
+	status int
+	reason string
+	err    error
+}
+
+// anyStatus is the status of the failures matching fail responses of any
+// status code.
+const anyStatus = 0
+
+// failureError returns the error the failures map the given status code
+// and errorReason to. Failures with a matching status take precedence over
+// the ones of anyStatus and, among those, failures with a matching reason
+// take precedence over the ones with none. ErrRESTServerError is returned
+// if none match.
+func failureError(failures []failure, status int, reason string) error {
+	var matched error
+	best := -1
+	for _, f := range failures {
+		rank := 0
+		switch f.status {
+		case status:
+			rank += 2
+		case anyStatus:
+		default:
+			continue
+		}
+		switch f.reason {
+		case reason:
+			rank++
+		case "":
+		default:
+			continue
+		}
+		if rank > best {
+			matched, best = f.err, rank
+		}
+	}
+	if matched != nil {
+		return matched
+	}
+	return ErrRESTServerError
+}
+
+// statusFailures returns the failures that don't match any status.
+func statusFailures(failures []failure) []failure {
+	var matching []failure
+	for _, f := range failures {
+		if f.status != anyStatus {
+			matching = append(matching, f)
+		}
+	}
+	return matching
+}
+
+type endpointKey struct{}
+
+// EndpointFromContext returns the name of the endpoint, e.g.
+// "UserService.GetUser", of the service method that made the request bound
+// to ctx. Middleware can use it to tell the requests apart.
+func EndpointFromContext(ctx context.Context) string {
+	endpoint, _ := ctx.Value(endpointKey{}).(string)
+	return endpoint
+}
+
+// call sends the request of the given endpoint and decodes the data of the
+// jSend response into v. v can be nil if the data is of no interest. Fail
+// responses are mapped to errors using the given failures and every other
+// unexpected response is reported as ErrRESTServerError. It's what the
+// generated service methods are built upon.
+func (c *Client) call(req *http.Request, endpoint string, failures []failure, v interface{}) (err error) {
+	req = req.WithContext(context.WithValue(req.Context(), endpointKey{}, endpoint))
+	if c.CallObserver != nil {
+		start := time.Now()
+		defer func() {
+			c.CallObserver(endpoint, time.Since(start), err)
+		}()
+	}
+
+	js, statusCode, err := c.do(req)
+	if err != nil {
+		// 401s and 403s fail in do but the endpoint might
+		// have a more specific error for them
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if mapped := failureError(statusFailures(failures), apiErr.StatusCode, apiErr.Reason); mapped != ErrRESTServerError {
+				// coalesced requests share the error
+				remapped := *apiErr
+				remapped.Err = mapped
+				return &remapped
+			}
+		}
+		return err
+	}
+
+	switch js.Status {
+	case "success":
+	case "fail":
+		jF, ok := js.Data.(*jSendFailData)
+		if !ok {
+			return newAPIError(req, js, statusCode, ErrRESTServerError)
+		}
+		c.Logger.Debug("REST server rejected the request",
+			logging.F("endpoint", EndpointFromContext(req.Context())),
+			logging.F("rest_status", statusCode),
+			logging.F("reason", jF.ErrorReason),
+			logging.F("reason_message", jF.ErrorMessage))
+		return newAPIError(req, js, statusCode, failureError(failures, statusCode, jF.ErrorReason))
+	default:
+		return newAPIError(req, js, statusCode, ErrRESTServerError)
+	}
+
+	if v == nil {
+		return nil
+	}
+	data, ok := js.Data.(*json.RawMessage)
+	if !ok {
+		return newAPIError(req, js, statusCode, ErrRESTServerError)
+	}
+	if err := json.Unmarshal(*data, v); err != nil {
+		return newAPIError(req, js, statusCode, ErrRESTServerError)
+	}
+	return nil
+}
+
+// searchQuery returns the query parameters the endpoints listing entities
+// take. Only the parameters with values other than the defaults are set.
+func searchQuery(pattern, by string, params PaginateParams) url.Values {
+	queries := url.Values{}
+	if params.Limit != 0 || params.Offset != 0 {
+		queries.Set("limit", strconv.FormatUint(uint64(params.Limit), 10))
+		queries.Set("offset", strconv.FormatUint(uint64(params.Offset), 10))
+	}
+	if pattern != "" {
+		queries.Set("pattern", url.QueryEscape(pattern))
+	}
+	if by != "" {
+		if params.SortOrder != "" {
+			by = fmt.Sprintf("%s_%s", by, params.SortOrder)
+		}
+		queries.Set("sort", by)
+	}
+	return queries
+}
@@ -4,29 +4,77 @@ package issue1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
-	"mime/multipart"
 	"net"
 	"net/http"
-	"net/textproto"
 	"net/url"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
 )
 
-/*
-type BadDataError struct {
-	Field string
+// APIError is the error returned by the services when the REST server
+// doesn't respond with success. It wraps one of the sentinel errors defined
+// by this package which means one can still use errors.Is to check what went
+// wrong while having access to the details returned by the REST server.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Status is the jSend status of the response. Either "fail" or "error"
+	// and empty if the response wasn't a jSend one.
+	Status string
+	// Reason is the errorReason of jSend fail responses. It usually names
+	// the field responsible for the failure.
+	Reason string
+	// Message is the errorMessage of jSend fail responses or the message
+	// of jSend error responses.
 	Message string
+	// Method and Path identify the request that caused the error.
+	Method, Path string
+	// Err is the sentinel error describing the error.
+	Err error
 }
 
-func (e BadDataError) Error() string {
-	return fmt.Sprintf("field: %s message:%s", e.Field, e.Message)
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%v (%s %s responded %d", e.Err, e.Method, e.Path, e.StatusCode)
+	if e.Reason != "" {
+		msg += fmt.Sprintf(", reason: %s", e.Reason)
+	}
+	if e.Message != "" {
+		msg += fmt.Sprintf(", message: %s", e.Message)
+	}
+	return msg + ")"
+}
+
+// Unwrap returns the sentinel error wrapped by the APIError.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// newAPIError wraps the given sentinel error with the details found on
+// the given request and response. js can be nil.
+func newAPIError(req *http.Request, js *jSendResponse, statusCode int, err error) error {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Err:        err,
+	}
+	if js != nil {
+		apiErr.Status = js.Status
+		apiErr.Message = js.Message
+		if jF, ok := js.Data.(*jSendFailData); ok {
+			apiErr.Reason = jF.ErrorReason
+			apiErr.Message = jF.ErrorMessage
+		}
+	}
+	return apiErr
 }
-*/
 
 // these errors shouldn't be used outside this package
 var (
@@ -97,7 +145,38 @@ type Client struct {
 	HTTPClient *http.Client
 	BaseURL    *url.URL
 
-	Logger         *log.Logger
+	// Logger logs the failures reported by the REST server. Nothing is
+	// logged if it's nil.
+	Logger *logging.Logger
+	// RetryPolicy specifies how requests that fail due to transient errors
+	// are retried. Requests aren't retried if it's nil.
+	RetryPolicy *RetryPolicy
+	// CircuitBreaker stops requests from being sent to the REST server
+	// while it's failing. It's disabled if nil.
+	CircuitBreaker *CircuitBreaker
+	// Cache caches the responses of GET requests. Responses aren't cached
+	// if it's nil.
+	Cache *ResponseCache
+	// RateLimiter limits the rate at which requests are sent to the REST
+	// server. Requests aren't limited if it's nil.
+	RateLimiter *RateLimiter
+	// Coalescer dedupes identical GET requests in flight at the same time.
+	// Requests aren't deduped if it's nil.
+	Coalescer *Coalescer
+	// BatchConcurrency is the maximum number of requests the batch methods
+	// send at the same time. DefaultBatchConcurrency is used if it's zero.
+	BatchConcurrency int
+	// MaxImageSize is the size in bytes of the largest image that can be
+	// uploaded. DefaultMaxImageSize is used if it's zero.
+	MaxImageSize int64
+	// CallObserver is called after every request sent by a service method
+	// with the name of its endpoint, e.g. "UserService.GetUser", how long it
+	// took and the error it returned. It's ignored if nil.
+	CallObserver func(endpoint string, d time.Duration, err error)
+	// Middleware wraps the transport of the HTTPClient for every request
+	// sent. The first one is the outermost. Use Use to add to it.
+	Middleware []Middleware
+
 	ChannelService ChannelService
 	UserService    UserService
 	FeedService    FeedService
@@ -109,7 +188,7 @@ type Client struct {
 }
 
 // NewClient returns a new issue1 client.
-func NewClient(httpClient *http.Client, baseURL *url.URL, logger *log.Logger) *Client {
+func NewClient(httpClient *http.Client, baseURL *url.URL, logger *logging.Logger) *Client {
 	c := &Client{HTTPClient: httpClient,
 		BaseURL: baseURL,
 		Logger:  logger,
@@ -125,22 +204,25 @@ func NewClient(httpClient *http.Client, baseURL *url.URL, logger *log.Logger) *C
 	return c
 }
 
-func (c *Client) newRequest(path string, method string) *http.Request {
+// newRequest returns a request bound to the given context. The context
+// will be used for the whole lifetime of the request, cancelling it will
+// abort the request.
+func (c *Client) newRequest(ctx context.Context, path string, method string) *http.Request {
 	req := &http.Request{
 		Method: method,
 		URL:    c.BaseURL.ResolveReference(&url.URL{Path: path}),
 		Header: make(http.Header),
 	}
-	return req
+	return req.WithContext(ctx)
 }
 
-func (c *Client) newRequestFromURL(u *url.URL, method string) *http.Request {
+func (c *Client) newRequestFromURL(ctx context.Context, u *url.URL, method string) *http.Request {
 	req := &http.Request{
 		Method: method,
 		URL:    c.BaseURL.ResolveReference(u),
 		Header: make(http.Header),
 	}
-	return req
+	return req.WithContext(ctx)
 }
 
 func addBodyToRequestAsJSON(req *http.Request, body interface{}) error {
@@ -150,71 +232,19 @@ func addBodyToRequestAsJSON(req *http.Request, body interface{}) error {
 		return err
 	}
 
-	req.Body = ioutil.NopCloser(buf)
-	req.ContentLength = int64(buf.Len())
+	setRequestBody(req, buf.Bytes())
 	req.Header.Add("Content-Type", "application/json")
 	return nil
 }
 
-func addImageToRequest(req *http.Request, image io.Reader, imageName string) error {
-	buf := new(bytes.Buffer)
-	//if req.Body != nil {
-	//	_, err := io.Copy(buf, req.Body)
-	//	if err != nil {
-	//		return err
-	//	}
-	//}
-	mw := multipart.NewWriter(buf)
-	defer mw.Close()
-	fw, err := mw.CreateFormFile("image", imageName)
-	if err != nil {
-		return err
-	}
-	_, err = io.Copy(fw, image)
-	if err != nil {
-		return err
+// setRequestBody sets the given bytes as the body of the request in a way
+// that allows it to be re-read if the request gets retried.
+func setRequestBody(req *http.Request, body []byte) {
+	req.ContentLength = int64(len(body))
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
 	}
-	req.Body = ioutil.NopCloser(buf)
-	req.Header.Set("Content-Type", mw.FormDataContentType())
-	return nil
-}
-
-func addJSONAndImageToRequestAsMultipart(req *http.Request, body interface{}, image io.Reader, imageName string) error {
-	buf := new(bytes.Buffer)
-	//if req.Body != nil {
-	//	_, err := io.Copy(buf, req.Body)
-	//	if err != nil {
-	//		return err
-	//	}
-	//}
-	mw := multipart.NewWriter(buf)
-	defer mw.Close()
-
-	jsonHeader := make(textproto.MIMEHeader)
-	jsonHeader.Set("Content-Type", "application/json")
-	jsonHeader.Set("Content-Disposition", `form-data; name="JSON"`)
-
-	jw, err := mw.CreatePart(jsonHeader)
-	if err != nil {
-		return err
-	}
-	err = json.NewEncoder(jw).Encode(body)
-	if err != nil {
-		return err
-	}
-
-	fw, err := mw.CreateFormFile("image", imageName)
-	if err != nil {
-		return err
-	}
-	_, err = io.Copy(fw, image)
-	if err != nil {
-		return err
-	}
-
-	req.Body = ioutil.NopCloser(buf)
-	req.Header.Set("Content-Type", mw.FormDataContentType())
-	return nil
 }
 
 func addJWTToRequest(req *http.Request, token string) {
@@ -262,40 +292,96 @@ func (c *Client) do(req *http.Request) (*jSendResponse, error) {
 }
 */
 
+// do sends the request, passing it through the Coalescer and the
+// CircuitBreaker if the client has them, and decodes the jSend response.
 func (c *Client) do(req *http.Request) (*jSendResponse, int, error) {
-	var err error
-	jSend := new(jSendResponse)
-	//c.Logger.Printf("request: %s %s\n", req.Method, req.URL.String())
-	/*
-		{
-			err = req.Clone(req.Context()).Write(os.Stdout)
-			if err != nil {
-				c.Logger.Printf("err: %v\n", err)
-			}
-		}*/
-	resp, err := c.HTTPClient.Do(req)
+	if c.Coalescer != nil {
+		return c.Coalescer.do(req, c.doBreaker)
+	}
+	return c.doBreaker(req)
+}
+
+// doBreaker sends the request through the CircuitBreaker if the client has
+// one. Responses served fresh from the Cache never reach the server so they
+// bypass the CircuitBreaker and are served even if its circuit is open.
+func (c *Client) doBreaker(req *http.Request) (*jSendResponse, int, error) {
+	if c.CircuitBreaker == nil {
+		return c.doRequest(req)
+	}
+	if resp, ok := c.freshCached(req); ok {
+		return c.decodeResponse(req, resp)
+	}
+	group := c.endpointGroup(req)
+	if err := c.CircuitBreaker.allow(group); err != nil {
+		return nil, -1, err
+	}
+	js, statusCode, err := c.doRequest(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// the request was abandoned, it says nothing about the server
+		c.CircuitBreaker.abandon(group)
+	case errors.Is(err, ErrConnectionError):
+		c.CircuitBreaker.record(group, true)
+	case err != nil && statusCode == -1:
+		// and neither does one that failed on our side, e.g. rate
+		// limited, missing from the cassette or an aborted upload
+		c.CircuitBreaker.abandon(group)
+	case err != nil && js == nil:
+		c.CircuitBreaker.record(group, isServerFailure(statusCode, nil))
+	default:
+		c.CircuitBreaker.record(group, isServerFailure(statusCode, js))
+	}
+	return js, statusCode, err
+}
+
+func (c *Client) doRequest(req *http.Request) (*jSendResponse, int, error) {
+	resp, err := c.sendCached(req)
 	if err != nil {
+		// a cancelled or timed out context shouldn't be reported
+		// as a connection error
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, -1, ctxErr
+		}
+		// replayed requests never reach the server
+		if errors.Is(err, ErrCassetteMiss) {
+			return nil, -1, err
+		}
+		// and neither do the uploads aborted on our side
+		var upErr *uploadError
+		if errors.As(err, &upErr) {
+			return nil, -1, upErr.err
+		}
 		if _, ok := err.(net.Error); ok {
 			return nil, -1, ErrConnectionError
 		}
 		return nil, -1, err
 	}
+	return c.decodeResponse(req, resp)
+}
+
+// decodeResponse decodes the jSend response to the request and closes its
+// body.
+func (c *Client) decodeResponse(req *http.Request, resp *http.Response) (*jSendResponse, int, error) {
+	jSend := new(jSendResponse)
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, resp.StatusCode, ErrAccessDenied
+		// the body might hold the reason, it's of no consequence if it doesn't
+		if json.NewDecoder(resp.Body).Decode(jSend) != nil {
+			jSend = nil
+		}
+		return nil, resp.StatusCode, newAPIError(req, jSend, resp.StatusCode, ErrAccessDenied)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		if json.NewDecoder(resp.Body).Decode(jSend) != nil {
+			jSend = nil
+		}
+		return nil, resp.StatusCode, newAPIError(req, jSend, resp.StatusCode, ErrForbiddenAccess)
 	}
 
-	//buf := new(bytes.Buffer)
-	//_, err = io.Copy(buf, resp.Body)
-	//fmt.Printf("Body:\n %s", string(buf.Bytes()))
-	//err = json.NewDecoder(buf).Decode(jSend)
-
-	err = json.NewDecoder(resp.Body).Decode(jSend)
+	err := json.NewDecoder(resp.Body).Decode(jSend)
 	if err != nil {
-		return nil, -1, ErrRESTServerError
+		return nil, resp.StatusCode, newAPIError(req, nil, resp.StatusCode, ErrRESTServerError)
 	}
-	//c.Logger.Printf("statusCode: %d\n", resp.StatusCode)
-	//c.Logger.Printf("response: %+v\n", jSend)
 	return jSend, resp.StatusCode, nil
 }
 
@@ -329,13 +415,18 @@ func (j *jSendResponse) UnmarshalJSON(b []byte) error {
 	switch j.Status {
 	case "success":
 		// if successful, they'll have to unmarshal the RawMessage
-		// itself to the type they want.
-		j.Data = doppelganger.Data
+		// itself to the type they want. Data is left nil if the
+		// response has none so that their type assertions fail.
+		if doppelganger.Data != nil {
+			j.Data = doppelganger.Data
+		}
 	case "fail":
 		failStruct := new(jSendFailData)
-		err := json.Unmarshal(*doppelganger.Data, failStruct)
-		if err != nil {
-			return errJSONDeserializationFailed
+		if doppelganger.Data != nil {
+			err := json.Unmarshal(*doppelganger.Data, failStruct)
+			if err != nil {
+				return errJSONDeserializationFailed
+			}
 		}
 		j.Data = failStruct
 	case "error":
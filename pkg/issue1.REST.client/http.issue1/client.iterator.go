@@ -0,0 +1,226 @@
+package issue1
+
+import (
+	"context"
+	"reflect"
+)
+
+// DefaultPageSize is the number of items the iterators request per page if
+// IteratorOptions.PageSize isn't set.
+const DefaultPageSize = 25
+
+// IteratorOptions is used to configure the iterators returned by the
+// Iterate methods of the services.
+type IteratorOptions struct {
+	// PageSize is the number of items requested per page.
+	PageSize uint
+	// SortOrder is the order the items are sorted with.
+	SortOrder SortOrder
+	// Prefetch makes the iterator fetch the next page in the background
+	// while the current one is being iterated over.
+	Prefetch bool
+}
+
+// pageFetcher fetches the page described by the given params and returns
+// it along with the number of items it holds.
+type pageFetcher func(ctx context.Context, params PaginateParams) (page interface{}, n int, err error)
+
+type pageResult struct {
+	page interface{}
+	n    int
+	err  error
+}
+
+// Iterator walks through the items of an endpoint one page at a time until
+// it returns an empty page or the same page as the previous one, which
+// servers ignoring or clamping the offset do. The typed iterators embed it and add accessors
+// for the current item.
+//
+//	it := c.UserService.IterateUsers(ctx, "", issue1.SortUsersByUsername, issue1.IteratorOptions{})
+//	for it.Next() {
+//		user := it.User()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	ctx      context.Context
+	fetch    pageFetcher
+	params   PaginateParams
+	prefetch bool
+
+	done       bool
+	err        error
+	prefetched chan pageResult
+
+	// page holds the n items of the current page, i is the current one
+	page interface{}
+	n, i int
+}
+
+func newIterator(ctx context.Context, opts IteratorOptions, fetch pageFetcher) Iterator {
+	if opts.PageSize == 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	return Iterator{
+		ctx:   ctx,
+		fetch: fetch,
+		params: PaginateParams{
+			SortOrder: opts.SortOrder,
+			Limit:     opts.PageSize,
+		},
+		prefetch: opts.Prefetch,
+	}
+}
+
+// Next advances the iterator to the next item and reports whether there
+// was one. It fetches the next page if need be.
+func (it *Iterator) Next() bool {
+	for it.i+1 >= it.n {
+		if !it.nextPage() {
+			it.page, it.n = nil, 0
+			return false
+		}
+	}
+	it.i++
+	return true
+}
+
+// Err returns the error that stopped the iteration if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// nextPage moves to the next page and reports whether there was one. Pages
+// smaller than requested aren't taken as the last one as the server may
+// cap their size, a page repeating the previous one is.
+func (it *Iterator) nextPage() bool {
+	if it.done {
+		return false
+	}
+	var res pageResult
+	if it.prefetched != nil {
+		res = <-it.prefetched
+		it.prefetched = nil
+	} else {
+		res = it.fetchPage(it.params)
+	}
+	if res.err != nil || res.n == 0 || reflect.DeepEqual(res.page, it.page) {
+		it.err = res.err
+		it.done = true
+		return false
+	}
+	it.params.Offset += uint(res.n)
+	if it.prefetch {
+		it.prefetched = make(chan pageResult, 1)
+		go func(params PaginateParams) {
+			it.prefetched <- it.fetchPage(params)
+		}(it.params)
+	}
+	it.page, it.n, it.i = res.page, res.n, -1
+	return true
+}
+
+func (it *Iterator) fetchPage(params PaginateParams) pageResult {
+	if err := it.ctx.Err(); err != nil {
+		return pageResult{err: err}
+	}
+	page, n, err := it.fetch(it.ctx, params)
+	return pageResult{page, n, err}
+}
+
+// UserIterator iterates over users. See Iterator.
+type UserIterator struct {
+	Iterator
+}
+
+// User returns the current user. Only valid after Next returns true.
+func (it *UserIterator) User() *User {
+	return it.page.([]*User)[it.i]
+}
+
+// All returns all the remaining users.
+func (it *UserIterator) All() ([]*User, error) {
+	all := make([]*User, 0)
+	for it.Next() {
+		all = append(all, it.User())
+	}
+	return all, it.Err()
+}
+
+// ChannelIterator iterates over channels. See Iterator.
+type ChannelIterator struct {
+	Iterator
+}
+
+// Channel returns the current channel. Only valid after Next returns true.
+func (it *ChannelIterator) Channel() *Channel {
+	return it.page.([]*Channel)[it.i]
+}
+
+// All returns all the remaining channels.
+func (it *ChannelIterator) All() ([]*Channel, error) {
+	all := make([]*Channel, 0)
+	for it.Next() {
+		all = append(all, it.Channel())
+	}
+	return all, it.Err()
+}
+
+// PostIterator iterates over posts. See Iterator.
+type PostIterator struct {
+	Iterator
+}
+
+// Post returns the current post. Only valid after Next returns true.
+func (it *PostIterator) Post() *Post {
+	return it.page.([]*Post)[it.i]
+}
+
+// All returns all the remaining posts.
+func (it *PostIterator) All() ([]*Post, error) {
+	all := make([]*Post, 0)
+	for it.Next() {
+		all = append(all, it.Post())
+	}
+	return all, it.Err()
+}
+
+// ReleaseIterator iterates over releases. See Iterator.
+type ReleaseIterator struct {
+	Iterator
+}
+
+// Release returns the current release. Only valid after Next returns true.
+func (it *ReleaseIterator) Release() *Release {
+	return it.page.([]*Release)[it.i]
+}
+
+// All returns all the remaining releases.
+func (it *ReleaseIterator) All() ([]*Release, error) {
+	all := make([]*Release, 0)
+	for it.Next() {
+		all = append(all, it.Release())
+	}
+	return all, it.Err()
+}
+
+// CommentIterator iterates over comments. See Iterator.
+type CommentIterator struct {
+	Iterator
+}
+
+// Comment returns the current comment. Only valid after Next returns true.
+func (it *CommentIterator) Comment() *Comment {
+	return it.page.([]*Comment)[it.i]
+}
+
+// All returns all the remaining comments.
+func (it *CommentIterator) All() ([]*Comment, error) {
+	all := make([]*Comment, 0)
+	for it.Next() {
+		all = append(all, it.Comment())
+	}
+	return all, it.Err()
+}
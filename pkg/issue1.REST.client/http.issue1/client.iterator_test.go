@@ -0,0 +1,89 @@
+package issue1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUserIterator(t *testing.T) {
+	const total = 12
+	maxLimit := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if maxLimit > 0 && limit > maxLimit {
+			limit = maxLimit
+		}
+		users := make([]string, 0)
+		for i := offset; i < offset+limit && i < total; i++ {
+			users = append(users, fmt.Sprintf(`{"username":"user%d"}`, i))
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":[%s]}`, strings.Join(users, ","))
+	})
+	defer done()
+
+	for _, tc := range []struct {
+		prefetch bool
+		maxLimit int
+	}{
+		{false, 0},
+		{true, 0},
+		// pages smaller than requested aren't the last one
+		{false, 3},
+		{true, 3},
+	} {
+		prefetch := tc.prefetch
+		maxLimit = tc.maxLimit
+		t.Run(fmt.Sprintf("Prefetch=%v,MaxLimit=%d", prefetch, maxLimit), func(t *testing.T) {
+			it := c.UserService.IterateUsers(context.Background(), "", SortUsersByUsername,
+				IteratorOptions{PageSize: 5, Prefetch: prefetch})
+			users, err := it.All()
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if len(users) != total {
+				t.Fatalf("len(users) = %d, want %d", len(users), total)
+			}
+			for i, u := range users {
+				if want := fmt.Sprintf("user%d", i); u.Username != want {
+					t.Errorf("index %d: Username = %q, want %q", i, u.Username, want)
+				}
+			}
+			if it.Next() {
+				t.Error("Next() = true on an exhausted iterator")
+			}
+		})
+	}
+}
+
+func TestIteratorIgnoredOffset(t *testing.T) {
+	requests := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 10 {
+			t.Error("the iterator didn't stop on a repeated page")
+			_, _ = w.Write([]byte(`{"status":"success","data":[]}`))
+			return
+		}
+		// the offset is ignored, the first page is always returned
+		_, _ = w.Write([]byte(`{"status":"success","data":[{"username":"user0"},{"username":"user1"}]}`))
+	})
+	defer done()
+
+	for _, prefetch := range []bool{false, true} {
+		requests = 0
+		it := c.UserService.IterateUsers(context.Background(), "", SortUsersByUsername,
+			IteratorOptions{PageSize: 2, Prefetch: prefetch})
+		users, err := it.All()
+		if err != nil {
+			t.Fatalf("Prefetch=%v: err = %v", prefetch, err)
+		}
+		if len(users) != 2 {
+			t.Errorf("Prefetch=%v: len(users) = %d, want 2", prefetch, len(users))
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package issue1
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+// Middleware wraps the RoundTripper used to send requests to the REST server
+// allowing one to intercept the requests and responses.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as
+// http.RoundTrippers.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use appends the given middleware to the client's chain. Middleware added
+// first sees the requests first and the responses last.
+func (c *Client) Use(middleware ...Middleware) {
+	c.Middleware = append(c.Middleware, middleware...)
+}
+
+// httpClient returns the HTTPClient with its transport wrapped by the
+// client's Middleware.
+func (c *Client) httpClient() *http.Client {
+	if len(c.Middleware) == 0 {
+		return c.HTTPClient
+	}
+	transport := c.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		transport = c.Middleware[i](transport)
+	}
+	httpClient := *c.HTTPClient
+	httpClient.Transport = transport
+	return &httpClient
+}
+
+// RequestIDHeader is the header used by RequestIDMiddleware.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx that carries the given request ID.
+// RequestIDMiddleware uses it for the requests made with the returned
+// context instead of generating one so that requests to the REST server
+// can be correlated with the request that caused them.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDMiddleware returns Middleware that sets the RequestIDHeader on
+// requests that don't have it. The ID is taken from the request's context or
+// randomly generated if it doesn't have one.
+func RequestIDMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(RequestIDHeader) != "" {
+				return next.RoundTrip(req)
+			}
+			id := RequestIDFromContext(req.Context())
+			if id == "" {
+				id = newRequestID()
+			}
+			// RoundTrippers mustn't modify the request they're given
+			req = req.Clone(req.Context())
+			req.Header.Set(RequestIDHeader, id)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// LatencyMiddleware returns Middleware that calls record with the duration
+// of every request. statusCode is -1 if the request failed.
+func LatencyMiddleware(record func(req *http.Request, statusCode int, d time.Duration)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			statusCode := -1
+			if err == nil {
+				statusCode = resp.StatusCode
+			}
+			record(req, statusCode, time.Since(start))
+			return resp, err
+		})
+	}
+}
+
+// LoggingMiddleware returns Middleware that logs every request and its
+// outcome. Failed requests and the ones the REST server failed to serve are
+// logged as warnings. The fields of the logger carried by the context of the
+// request, e.g. the route that caused it, are added to the entries. Auth
+// tokens are redacted from the logged headers.
+func LoggingMiddleware(logger *logging.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			duration := time.Since(start)
+
+			fields := []logging.Field{
+				logging.F("method", req.Method),
+				logging.F("url", req.URL.String()),
+			}
+			if id := req.Header.Get(RequestIDHeader); id != "" {
+				fields = append(fields, logging.F("request_id", id))
+			}
+			if endpoint := EndpointFromContext(req.Context()); endpoint != "" {
+				fields = append(fields, logging.F("endpoint", endpoint))
+			}
+			if err != nil {
+				fields = append(fields, logging.Err(err))
+			} else {
+				fields = append(fields, logging.F("rest_status", resp.StatusCode))
+			}
+			fields = append(fields, logging.F("duration", duration))
+			header := RedactHeader(req.Header)
+			keys := make([]string, 0, len(header))
+			for key := range header {
+				if key != RequestIDHeader {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fields = append(fields, logging.F("header."+key, strings.Join(header[key], ", ")))
+			}
+
+			l := logger.With(logging.FromContext(req.Context()).Fields()...)
+			if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+				l.Warn("REST request failed", fields...)
+			} else {
+				l.Info("REST request", fields...)
+			}
+			return resp, err
+		})
+	}
+}
+
+// sensitiveHeaders are the headers RedactHeader redacts.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// RedactHeader returns a copy of the given header with the values of the
+// headers carrying credentials, like Authorization, redacted. The auth
+// scheme is kept so that "Bearer <token>" becomes "Bearer [REDACTED]".
+func RedactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range sensitiveHeaders {
+		values := redacted[key]
+		for i, v := range values {
+			if scheme := strings.SplitN(v, " ", 2); len(scheme) == 2 {
+				values[i] = scheme[0] + " [REDACTED]"
+			} else {
+				values[i] = "[REDACTED]"
+			}
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,69 @@
+package issue1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+func TestMiddleware(t *testing.T) {
+	var gotRequestID string
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID = r.Header.Get(RequestIDHeader)
+		_, _ = w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer done()
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	logs := new(bytes.Buffer)
+	var latencies []time.Duration
+	c.Use(
+		tag("first"),
+		RequestIDMiddleware(),
+		LoggingMiddleware(logging.New(logs, logging.FormatLogfmt)),
+		LatencyMiddleware(func(req *http.Request, statusCode int, d time.Duration) {
+			latencies = append(latencies, d)
+		}),
+		tag("last"),
+	)
+
+	ctx := WithRequestID(context.Background(), "abc123")
+	ctx = logging.NewContext(ctx, logging.New(nil, logging.FormatLogfmt).With(logging.F("route", "GET /u/:username")))
+	_, err := c.UserService.GetUserBookmarksContext(ctx, "loveless", "secret-token")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if strings.Join(order, ",") != "first,last" {
+		t.Errorf("order = %v, want [first last]", order)
+	}
+	if gotRequestID != "abc123" {
+		t.Errorf("request id = %q, want %q", gotRequestID, "abc123")
+	}
+	if len(latencies) != 1 {
+		t.Errorf("%d latencies recorded, want 1", len(latencies))
+	}
+	logged := logs.String()
+	if strings.Contains(logged, "secret-token") {
+		t.Errorf("token wasn't redacted: %s", logged)
+	}
+	for _, want := range []string{"method=GET", "request_id=abc123", "rest_status=200", "endpoint=UserService.GetUserBookmarks",
+		`route="GET /u/:username"`, `header.Authorization="Bearer [REDACTED]"`} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log %q doesn't contain %q", logged, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package issue1
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is wrapped by the *RateLimitError returned when a request
+// isn't sent because it would exceed the limits of the client's RateLimiter.
+var ErrRateLimited = errors.New("http.issue1: rate limit exceeded")
+
+// The endpoint groups a RateLimiter can limit separately.
+const (
+	// RateGroupSearch holds the requests to /search and the ones searching
+	// entities with a pattern.
+	RateGroupSearch = "search"
+	// RateGroupWrites holds the requests that aren't GET or HEAD requests.
+	RateGroupWrites = "writes"
+	// RateGroupReads holds all the other requests.
+	RateGroupReads = "reads"
+)
+
+// RateLimitError is returned when a request isn't sent because it would
+// exceed the limits of the client's RateLimiter.
+type RateLimitError struct {
+	// Scope is the limit that would've been exceeded. Either "global",
+	// "token" or the endpoint group of the request.
+	Scope string
+	// RetryAfter is how long until the request would be let through.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v (%s limit, retry after %v)", ErrRateLimited, e.Scope, e.RetryAfter)
+}
+
+// Unwrap returns ErrRateLimited.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Rate is the rate of a token bucket. It lets Limit requests through per
+// second on average and bursts of up to Burst requests. There's no limit if
+// Limit is zero.
+type Rate struct {
+	Limit float64
+	Burst int
+}
+
+// Every returns the Rate letting a request through every interval with
+// bursts of up to burst requests.
+func Every(interval time.Duration, burst int) Rate {
+	if interval <= 0 {
+		return Rate{}
+	}
+	return Rate{Limit: float64(time.Second) / float64(interval), Burst: burst}
+}
+
+func (r Rate) burst() float64 {
+	if r.Burst < 1 {
+		return 1
+	}
+	return float64(r.Burst)
+}
+
+// RateLimiter limits the rate at which the client sends requests to the
+// REST server using token buckets. A request is only sent once the global
+// bucket, the bucket of its endpoint group and the bucket of its auth token
+// all let it through. Every attempt of retried requests counts while the
+// responses served from the Cache don't.
+type RateLimiter struct {
+	// Global limits all the requests.
+	Global Rate
+	// Groups limits the requests of each endpoint group. See RateGroupSearch,
+	// RateGroupWrites and RateGroupReads.
+	Groups map[string]Rate
+	// PerToken limits the requests of each auth token separately so that a
+	// single user can't use up the limits of everyone.
+	PerToken Rate
+	// MaxWait is how long a request is held back waiting for the limits to
+	// let it through. Requests that would have to wait longer fail right
+	// away with a *RateLimitError. Requests never wait if it's zero.
+	MaxWait time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// maxIdleBuckets is the number of buckets after which full buckets, which
+// are the same as new ones, are dropped.
+const maxIdleBuckets = 1024
+
+// NewRateLimiter returns a RateLimiter that only limits requests globally
+// and holds them back for up to maxWait. Set the Groups and PerToken rates
+// to limit them further.
+func NewRateLimiter(global Rate, maxWait time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Global:  global,
+		Groups:  make(map[string]Rate),
+		MaxWait: maxWait,
+	}
+}
+
+type rateLimit struct {
+	scope, key string
+	rate       Rate
+}
+
+// limits returns the limits the request is subject to.
+func (rl *RateLimiter) limits(req *http.Request, group string) []rateLimit {
+	var limits []rateLimit
+	if rl.Global.Limit > 0 {
+		limits = append(limits, rateLimit{"global", "global", rl.Global})
+	}
+	if rate := rl.Groups[group]; rate.Limit > 0 {
+		limits = append(limits, rateLimit{group, "group " + group, rate})
+	}
+	if token := req.Header.Get("Authorization"); token != "" && rl.PerToken.Limit > 0 {
+		limits = append(limits, rateLimit{"token", "token " + token, rl.PerToken})
+	}
+	return limits
+}
+
+// wait takes a token from the buckets of the request, waiting for them to
+// be refilled if need be. It returns a *RateLimitError if it would have to
+// wait longer than MaxWait.
+func (rl *RateLimiter) wait(req *http.Request, group string) error {
+	limits := rl.limits(req, group)
+	if len(limits) == 0 {
+		return nil
+	}
+
+	rl.mu.Lock()
+	now := rl.timeNow()
+	var wait time.Duration
+	var scope string
+	for _, l := range limits {
+		if d := rl.bucket(l, now).delay(l.rate); d > wait {
+			wait, scope = d, l.scope
+		}
+	}
+	if wait > rl.MaxWait {
+		rl.mu.Unlock()
+		return &RateLimitError{Scope: scope, RetryAfter: wait}
+	}
+	// the tokens are taken right away, buckets going negative, so that
+	// the requests arriving while this one waits queue behind it
+	for _, l := range limits {
+		rl.buckets[l.key].tokens--
+	}
+	rl.prune(now)
+	rl.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		rl.mu.Lock()
+		for _, l := range limits {
+			if b, ok := rl.buckets[l.key]; ok {
+				b.tokens = math.Min(b.tokens+1, l.rate.burst())
+			}
+		}
+		rl.mu.Unlock()
+		return req.Context().Err()
+	}
+}
+
+// bucket returns the refilled bucket of the limit, creating it full if
+// need be. rl.mu must be held.
+func (rl *RateLimiter) bucket(l rateLimit, now time.Time) *bucket {
+	if rl.buckets == nil {
+		rl.buckets = make(map[string]*bucket)
+	}
+	b, ok := rl.buckets[l.key]
+	if !ok {
+		b = &bucket{tokens: l.rate.burst(), last: now}
+		rl.buckets[l.key] = b
+		return b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed.Seconds()*l.rate.Limit, l.rate.burst())
+		b.last = now
+	}
+	return b
+}
+
+// delay returns how long until the bucket holds a whole token.
+func (b *bucket) delay(rate Rate) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate.Limit * float64(time.Second))
+}
+
+// prune drops the buckets of the tokens that have been refilled once there
+// are too many of them. rl.mu must be held.
+func (rl *RateLimiter) prune(now time.Time) {
+	if len(rl.buckets) <= maxIdleBuckets {
+		return
+	}
+	for key, b := range rl.buckets {
+		if !strings.HasPrefix(key, "token ") {
+			continue
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*rl.PerToken.Limit >= rl.PerToken.burst() {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (rl *RateLimiter) timeNow() time.Time {
+	if rl.now != nil {
+		return rl.now()
+	}
+	return time.Now()
+}
+
+// rateGroup returns the endpoint group the request is rate limited by.
+func (c *Client) rateGroup(req *http.Request) string {
+	switch {
+	case c.endpointGroup(req) == "search" || req.URL.Query().Get("pattern") != "":
+		return RateGroupSearch
+	case req.Method != http.MethodGet && req.Method != http.MethodHead:
+		return RateGroupWrites
+	default:
+		return RateGroupReads
+	}
+}
+
+// waitRateLimit holds the request back until the client's RateLimiter
+// lets it through.
+func (c *Client) waitRateLimit(req *http.Request) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	return c.RateLimiter.wait(req, c.rateGroup(req))
+}
@@ -0,0 +1,117 @@
+package issue1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	requests := 0
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless"}}`))
+	})
+	defer done()
+
+	t.Run("FailsFast", func(t *testing.T) {
+		now := time.Now()
+		c.RateLimiter = NewRateLimiter(Rate{}, 0)
+		c.RateLimiter.PerToken = Every(time.Second, 2)
+		c.RateLimiter.now = func() time.Time { return now }
+		requests = 0
+
+		for i := 0; i < 2; i++ {
+			if _, err := c.UserService.GetUserAuthorized("loveless", "slowdive"); err != nil {
+				t.Fatalf("request %d: err = %v", i+1, err)
+			}
+		}
+		_, err := c.UserService.GetUserAuthorized("loveless", "slowdive")
+		var rlErr *RateLimitError
+		if !errors.As(err, &rlErr) || !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("err = %v, want a *RateLimitError", err)
+		}
+		if rlErr.Scope != "token" || rlErr.RetryAfter != time.Second {
+			t.Errorf("err = %+v", rlErr)
+		}
+		if requests != 2 {
+			t.Errorf("%d requests were sent, want 2", requests)
+		}
+		// the buckets of other tokens are untouched
+		if _, err := c.UserService.GetUserAuthorized("loveless", "ride"); err != nil {
+			t.Errorf("other tokens were affected: %v", err)
+		}
+
+		now = now.Add(time.Second)
+		if _, err := c.UserService.GetUserAuthorized("loveless", "slowdive"); err != nil {
+			t.Errorf("err after refill = %v", err)
+		}
+	})
+	t.Run("LimitsGroups", func(t *testing.T) {
+		now := time.Now()
+		c.RateLimiter = NewRateLimiter(Rate{}, 0)
+		c.RateLimiter.Groups[RateGroupSearch] = Every(time.Minute, 1)
+		c.RateLimiter.now = func() time.Time { return now }
+
+		// the response doesn't matter, only that the request was sent
+		if _, err := c.UserService.SearchUsers("kevin", SortUsersByUsername, PaginateParams{}); errors.Is(err, ErrRateLimited) {
+			t.Fatal(err)
+		}
+		_, err := c.SearchService.Search("kevin", "", PaginateParams{})
+		var rlErr *RateLimitError
+		if !errors.As(err, &rlErr) || rlErr.Scope != RateGroupSearch {
+			t.Errorf("err = %v, want a search *RateLimitError", err)
+		}
+		if _, err := c.UserService.GetUser("loveless"); err != nil {
+			t.Errorf("reads were affected: %v", err)
+		}
+	})
+	t.Run("Waits", func(t *testing.T) {
+		c.RateLimiter = NewRateLimiter(Every(20*time.Millisecond, 1), time.Second)
+		requests = 0
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if _, err := c.UserService.GetUser("loveless"); err != nil {
+				t.Fatalf("request %d: err = %v", i+1, err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+			t.Errorf("3 requests took %v, want at least 40ms", elapsed)
+		}
+		if requests != 3 {
+			t.Errorf("%d requests were sent, want 3", requests)
+		}
+	})
+	t.Run("WaitIsCancelled", func(t *testing.T) {
+		c.RateLimiter = NewRateLimiter(Every(time.Minute, 1), time.Hour)
+		if _, err := c.UserService.GetUser("loveless"); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := c.UserService.GetUserContext(ctx, "loveless"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+	t.Run("DoesNotTripCircuitBreaker", func(t *testing.T) {
+		c.RateLimiter = NewRateLimiter(Every(time.Minute, 1), 0)
+		if _, err := c.UserService.GetUser("loveless"); err != nil {
+			t.Fatal(err)
+		}
+		c.CircuitBreaker = NewCircuitBreaker(1, time.Minute)
+		defer func() { c.CircuitBreaker = nil }()
+		c.CircuitBreaker.circuit("users").state = CircuitOpen
+
+		// the probe of the half-open circuit is rate limited and says
+		// nothing about the server
+		if _, err := c.UserService.GetUser("loveless"); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("err = %v, want %v", err, ErrRateLimited)
+		}
+		if got := c.CircuitBreaker.State("users"); got != CircuitHalfOpen {
+			t.Errorf("state = %v, want %v", got, CircuitHalfOpen)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package issue1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy specifies how the client retries requests that failed because
+// of transient errors: connection errors and gateway errors from the REST
+// servers.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request will be sent,
+	// the first attempt included. Values below 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the time waited before the first retry. It's doubled
+	// on every subsequent retry until it reaches MaxBackoff.
+	MinBackoff, MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, of the backoff that's
+	// randomized so that clients don't retry in lockstep.
+	Jitter float64
+	// IdempotentMethods are the HTTP methods that are safe to retry. Requests
+	// using other methods are never retried.
+	IdempotentMethods []string
+	// RetryStatusCodes are the response status codes considered transient.
+	RetryStatusCodes []int
+}
+
+// DefaultRetryPolicy returns a RetryPolicy that retries idempotent requests
+// up to three times on connection errors and 502, 503 and 504 responses.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Jitter:      0.5,
+		IdempotentMethods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions,
+			http.MethodPut,
+			http.MethodDelete,
+		},
+		RetryStatusCodes: []int{
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p *RetryPolicy) idempotent(method string) bool {
+	for _, m := range p.IdempotentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, code := range p.RetryStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the time to wait before the given retry. The first
+// retry is 1.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.MinBackoff) * math.Pow(2, float64(retry-1))
+	if max := float64(p.MaxBackoff); p.MaxBackoff > 0 && d > max {
+		d = max
+	}
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// send sends the request using the HTTPClient and the Middleware, retrying it according to the
+// client's RetryPolicy. Every attempt waits for the client's RateLimiter to let it through.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	httpClient := c.httpClient()
+	policy := c.RetryPolicy
+	do := func() (*http.Response, error) {
+		if err := c.waitRateLimit(req); err != nil {
+			return nil, err
+		}
+		return httpClient.Do(req)
+	}
+	if policy == nil || policy.MaxAttempts < 2 || !policy.idempotent(req.Method) {
+		return do()
+	}
+	// requests with bodies can only be retried if the body can be re-read
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return do()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		resp, err := do()
+		if attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		wait := policy.backoff(attempt)
+		switch {
+		case err != nil:
+			if req.Context().Err() != nil {
+				return resp, err
+			}
+			if _, ok := err.(net.Error); !ok {
+				return resp, err
+			}
+			var upErr *uploadError
+			if errors.As(err, &upErr) {
+				return resp, err
+			}
+		case policy.retryableStatus(resp.StatusCode):
+			transient, err := isTransientResponse(resp)
+			if err != nil {
+				return nil, err
+			}
+			if !transient {
+				return resp, nil
+			}
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if policy.MaxBackoff > 0 && retryAfter > policy.MaxBackoff {
+					// the server won't be back before we'd give up anyways
+					return resp, nil
+				}
+				if retryAfter > wait {
+					wait = retryAfter
+				}
+			}
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// isTransientResponse reports whether the given response with a retryable
+// status code describes a transient error. The REST server uses some of these
+// codes for jSend fail responses, e.g. 503 when a channel's stickied posts
+// are full, which mustn't be retried. The body of the response is buffered
+// so it can still be read by the caller.
+func isTransientResponse(resp *http.Response) (bool, error) {
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return false, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	var js struct {
+		Status string `json:"status"`
+	}
+	if json.Unmarshal(b, &js) == nil && js.Status == "fail" {
+		return false, nil
+	}
+	return true, nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header which can either
+// be in seconds or a HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
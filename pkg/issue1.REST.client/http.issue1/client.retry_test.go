@@ -0,0 +1,77 @@
+package issue1
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+
+	t.Run("RetriesGatewayErrors", func(t *testing.T) {
+		attempts := 0
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			body, _ := ioutil.ReadAll(r.Body)
+			if !bytes.Contains(body, []byte(`"name":"Chromagnum"`)) {
+				t.Errorf("attempt %d: body = %q, was not replayed", attempts, body)
+			}
+			if attempts < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":{"username":"chromagnum","name":"Chromagnum"}}`))
+		})
+		defer done()
+		c.RetryPolicy = policy
+
+		_, err := c.ChannelService.UpdateChannel("chromagnum", &Channel{Name: "Chromagnum"}, "token")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("attempts = %d, want 3", attempts)
+		}
+	})
+	t.Run("DoesNotRetryNonIdempotent", func(t *testing.T) {
+		attempts := 0
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		defer done()
+		c.RetryPolicy = policy
+
+		_, err := c.UserService.AddUser(&User{Username: "loveless", Email: "kevin@mbv.ie", FirstName: "Kevin", Password: "password"})
+		if err == nil {
+			t.Fatal("err = nil")
+		}
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+	t.Run("DoesNotRetryJSendFail", func(t *testing.T) {
+		attempts := 0
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"fail","data":{"errorReason":"Stickied postID"}}`))
+		})
+		defer done()
+		c.RetryPolicy = policy
+
+		err := c.ChannelService.StickyPost("chromagnum", 3, "token")
+		if !errors.Is(err, ErrStickiedPostFull) {
+			t.Errorf("err = %v, want %v", err, ErrStickiedPostFull)
+		}
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+}
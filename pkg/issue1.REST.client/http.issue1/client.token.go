@@ -0,0 +1,80 @@
+package issue1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// TokenSource supplies the auth token of a user and stores the new ones
+// obtained when the client refreshes it.
+type TokenSource interface {
+	// Token returns the current auth token.
+	Token() string
+	// SetToken persists a refreshed auth token. It's called before the
+	// request that needed the refresh is retried.
+	SetToken(token string) error
+}
+
+type tokenSourceKey struct{}
+
+// WithTokenSource returns a copy of ctx that carries the given TokenSource.
+// When a request made with the returned context is denied access, the
+// client refreshes the token of the TokenSource using the AuthService,
+// passes the new token to SetToken and retries the request once using it.
+// The error is returned as usual if the refresh fails.
+func WithTokenSource(ctx context.Context, ts TokenSource) context.Context {
+	return context.WithValue(ctx, tokenSourceKey{}, ts)
+}
+
+func tokenSourceFromContext(ctx context.Context) TokenSource {
+	ts, _ := ctx.Value(tokenSourceKey{}).(TokenSource)
+	return ts
+}
+
+// sendAuthorized sends the request and, if it's denied access and the
+// request's context carries a TokenSource, refreshes the auth token and
+// sends the request again.
+func (c *Client) sendAuthorized(req *http.Request) (*http.Response, error) {
+	resp, err := c.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	ts := tokenSourceFromContext(req.Context())
+	if ts == nil || req.Header.Get("Authorization") == "" {
+		return resp, nil
+	}
+	// requests with bodies can only be resent if the body can be re-read
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// the context is stripped of the token source so that the refresh
+	// request being denied doesn't lead to another refresh
+	token, err := c.AuthService.RefreshAuthTokenContext(
+		WithTokenSource(req.Context(), nil), ts.Token())
+	if err != nil {
+		if errors.Is(err, ErrAccessDenied) {
+			// the token is too old to be refreshed, the caller will have to
+			// handle the original response
+			return resp, nil
+		}
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+
+	err = ts.SetToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		req.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Del("Authorization")
+	addJWTToRequest(req, token)
+	return c.send(req)
+}
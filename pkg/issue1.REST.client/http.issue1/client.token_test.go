@@ -0,0 +1,75 @@
+package issue1
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+type testTokenSource struct {
+	token string
+}
+
+func (ts *testTokenSource) Token() string { return ts.token }
+
+func (ts *testTokenSource) SetToken(token string) error {
+	ts.token = token
+	return nil
+}
+
+func TestTokenSource(t *testing.T) {
+	var refreshable bool
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		switch {
+		case r.URL.Path == "/token-auth-refresh" && auth == "Bearer old" && refreshable:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"token":"new"}}`))
+		case r.URL.Path == "/token-auth-refresh":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":"error","message":"token expired"}`))
+		case auth != "Bearer new":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			if len(body) == 0 {
+				t.Error("body wasn't resent")
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":{"id":1,"content":"hello"}}`))
+		}
+	})
+	defer done()
+
+	t.Run("Refreshes", func(t *testing.T) {
+		refreshable = true
+		ts := &testTokenSource{"old"}
+		ctx := WithTokenSource(context.Background(), ts)
+		_, err := c.CommentService.AddCommentContext(ctx, 1, &Comment{Content: "hello"}, ts.Token())
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if ts.token != "new" {
+			t.Errorf("token = %q, the refreshed token wasn't persisted", ts.token)
+		}
+	})
+	t.Run("RefreshDenied", func(t *testing.T) {
+		refreshable = false
+		ts := &testTokenSource{"old"}
+		ctx := WithTokenSource(context.Background(), ts)
+		_, err := c.CommentService.AddCommentContext(ctx, 1, &Comment{Content: "hello"}, ts.Token())
+		if !errors.Is(err, ErrAccessDenied) {
+			t.Fatalf("err = %v, want %v", err, ErrAccessDenied)
+		}
+		if ts.token != "old" {
+			t.Errorf("token = %q, want it unchanged", ts.token)
+		}
+	})
+	t.Run("WithoutTokenSource", func(t *testing.T) {
+		refreshable = true
+		_, err := c.CommentService.AddComment(1, &Comment{Content: "hello"}, "old")
+		if !errors.Is(err, ErrAccessDenied) {
+			t.Fatalf("err = %v, want %v", err, ErrAccessDenied)
+		}
+	})
+}
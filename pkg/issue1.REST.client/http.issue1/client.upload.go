@@ -0,0 +1,279 @@
+package issue1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxImageSize is the size of the largest image that can be uploaded
+// if the client's MaxImageSize isn't set.
+const DefaultMaxImageSize = 20 << 20
+
+// ErrImageTooLarge is returned when uploading an image larger than the
+// client's MaxImageSize.
+var ErrImageTooLarge = errors.New("http.issue1: image is larger than the maximum size")
+
+// acceptedImageTypes are the image types the REST server accepts.
+var acceptedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// UploadProgressFunc is called as an image is being uploaded with the number
+// of bytes of the image sent so far and its total size. The total is -1 if
+// the size of the image isn't known beforehand.
+type UploadProgressFunc func(sent, total int64)
+
+type uploadProgressKey struct{}
+
+// WithUploadProgress returns a copy of the context that makes the requests
+// bound to it report the progress of the images they upload to fn. Use it
+// with the Context variants of the methods uploading images:
+//
+//	ctx := issue1.WithUploadProgress(ctx, func(sent, total int64) {
+//		log.Printf("uploaded %d/%d bytes", sent, total)
+//	})
+//	release, err := c.ReleaseService.AddImageReleaseContext(ctx, r, page, "page.png", token)
+func WithUploadProgress(ctx context.Context, fn UploadProgressFunc) context.Context {
+	return context.WithValue(ctx, uploadProgressKey{}, fn)
+}
+
+// uploadError wraps the errors that abort an upload from the client's side
+// so that they aren't mistaken for connection errors.
+type uploadError struct {
+	err error
+}
+
+func (e *uploadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *uploadError) Unwrap() error {
+	return e.err
+}
+
+// addImageToRequest sets a multipart body holding the image as the body of
+// the request. The image is streamed as the request is sent instead of
+// being read into memory.
+func (c *Client) addImageToRequest(req *http.Request, image io.Reader, imageName string) error {
+	return c.addUploadToRequest(req, nil, image, imageName)
+}
+
+// addJSONAndImageToRequestAsMultipart is the same as addImageToRequest but
+// the body holds the JSON encoding of body as well.
+func (c *Client) addJSONAndImageToRequestAsMultipart(req *http.Request, body interface{}, image io.Reader, imageName string) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.addUploadToRequest(req, b, image, imageName)
+}
+
+// addUploadToRequest checks the type and the size of the image before
+// setting the streaming body on the request. Images that can be seeked are
+// sent again if the request gets retried, others aren't retried.
+func (c *Client) addUploadToRequest(req *http.Request, jsonPart []byte, image io.Reader, imageName string) error {
+	u := &imageUpload{
+		json:      jsonPart,
+		imageName: imageName,
+		boundary:  multipart.NewWriter(nil).Boundary(),
+		total:     -1,
+		max:       c.MaxImageSize,
+	}
+	if u.max == 0 {
+		u.max = DefaultMaxImageSize
+	}
+	u.progress, _ = req.Context().Value(uploadProgressKey{}).(UploadProgressFunc)
+
+	seeker, seekable := image.(io.Seeker)
+	var start int64
+	if seekable {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seekable = false
+		}
+	}
+	if size, ok := imageSize(image); ok {
+		u.total = size - start
+		if u.total > u.max {
+			return ErrImageTooLarge
+		}
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(image, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+	u.imageType = http.DetectContentType(head)
+	if !acceptedImageTypes[u.imageType] {
+		return fmt.Errorf("%w: %s", ErrUnacceptedImageType, u.imageType)
+	}
+
+	body := u.body(io.MultiReader(bytes.NewReader(head), image))
+	req.Body = body
+	req.ContentLength = -1
+	if seekable {
+		var mu sync.Mutex
+		req.GetBody = func() (io.ReadCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			// the previous attempt might still be reading the image if
+			// it was rejected before being sent whole
+			_ = body.Close()
+			<-body.done
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			body = u.body(image)
+			return body, nil
+		}
+	}
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+u.boundary)
+	return nil
+}
+
+// imageSize returns the size of the image if it can be known without
+// reading it.
+func imageSize(image io.Reader) (int64, bool) {
+	switch image := image.(type) {
+	case interface{ Size() int64 }:
+		return image.Size(), true
+	case *os.File:
+		if info, err := image.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size(), true
+		}
+	}
+	return 0, false
+}
+
+// imageUpload is a multipart body holding an optional JSON part and an
+// image.
+type imageUpload struct {
+	json      []byte
+	imageName string
+	imageType string
+	boundary  string
+	// total is the size of the image, -1 if unknown, and max the size of
+	// the largest one accepted.
+	total, max int64
+	progress   UploadProgressFunc
+}
+
+// body returns a body streaming the multipart encoding of the upload with
+// the image read from the given reader.
+func (u *imageUpload) body(image io.Reader) *streamBody {
+	return &streamBody{
+		write: func(w io.Writer) error {
+			return u.write(w, image)
+		},
+		done: make(chan struct{}),
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (u *imageUpload) write(w io.Writer, image io.Reader) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(u.boundary); err != nil {
+		return err
+	}
+	if u.json != nil {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Type", "application/json")
+		header.Set("Content-Disposition", `form-data; name="JSON"`)
+		jw, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err := jw.Write(u.json); err != nil {
+			return err
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition",
+		fmt.Sprintf(`form-data; name="image"; filename="%s"`, quoteEscaper.Replace(u.imageName)))
+	header.Set("Content-Type", u.imageType)
+	fw, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fw, &progressReader{upload: u, r: image}); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+// progressReader reports the progress of reading the image and stops
+// once it's read more than the maximum size.
+type progressReader struct {
+	upload *imageUpload
+	r      io.Reader
+	sent   int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.sent += int64(n)
+	if pr.sent > pr.upload.max {
+		return n, &uploadError{ErrImageTooLarge}
+	}
+	if err != nil && err != io.EOF {
+		err = &uploadError{err}
+	}
+	if n > 0 && pr.upload.progress != nil {
+		pr.upload.progress(pr.sent, pr.upload.total)
+	}
+	return n, err
+}
+
+// streamBody is a request body written by write as it's read. Nothing is
+// written until it's first read so a body that's never sent doesn't leave
+// a goroutine behind.
+type streamBody struct {
+	write func(w io.Writer) error
+	once  sync.Once
+	pr    *io.PipeReader
+	// done is closed once write has returned or if it's never called.
+	done chan struct{}
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		pr, pw := io.Pipe()
+		b.pr = pr
+		go func() {
+			defer close(b.done)
+			pw.CloseWithError(b.write(pw))
+		}()
+	})
+	if b.pr == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return b.pr.Read(p)
+}
+
+// Close stops the writing of the body. The writer fails on its next write
+// and done is closed once it has returned.
+func (b *streamBody) Close() error {
+	// a body closed before being read is never written
+	b.once.Do(func() { close(b.done) })
+	if b.pr == nil {
+		return nil
+	}
+	return b.pr.Close()
+}
@@ -0,0 +1,171 @@
+package issue1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// png is an image large enough to be sent in more than one read.
+var png = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte("shoegaze"), 64<<10)...)
+
+// onlyReader hides everything but the Read method of the reader so that
+// its size isn't known and it can't be seeked.
+type onlyReader struct {
+	r io.Reader
+}
+
+func (r onlyReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func TestUpload(t *testing.T) {
+	t.Run("StreamsMultipart", func(t *testing.T) {
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength != -1 {
+				t.Errorf("ContentLength = %d, the body wasn't streamed", r.ContentLength)
+			}
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatal(err)
+			}
+			if got := r.FormValue("JSON"); !strings.Contains(got, `"ownerChannel":"mbv"`) {
+				t.Errorf("JSON part = %s", got)
+			}
+			f, header, err := r.FormFile("image")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, _ := ioutil.ReadAll(f)
+			if !bytes.Equal(got, png) {
+				t.Errorf("image of %d bytes was received as %d bytes", len(png), len(got))
+			}
+			if header.Filename != "loveless.png" || header.Header.Get("Content-Type") != "image/png" {
+				t.Errorf("image header = %v", header.Header)
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":{"id":7}}`))
+		})
+		defer done()
+
+		var sent, total int64
+		ctx := WithUploadProgress(context.Background(), func(s, n int64) {
+			sent, total = s, n
+		})
+		rel, err := c.ReleaseService.AddImageReleaseContext(ctx, &Release{OwnerChannel: "mbv"}, onlyReader{bytes.NewReader(png)}, "loveless.png", "token")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if rel.ID != 7 {
+			t.Errorf("release = %+v", rel)
+		}
+		if sent != int64(len(png)) || total != -1 {
+			t.Errorf("progress ended at %d/%d, want %d/-1", sent, total, len(png))
+		}
+	})
+	t.Run("RejectsUnacceptedTypes", func(t *testing.T) {
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("the image was uploaded")
+		})
+		defer done()
+
+		_, err := c.UserService.AddPicture("loveless", strings.NewReader("only shallow"), "lyrics.txt", "token")
+		if !errors.Is(err, ErrUnacceptedImageType) {
+			t.Errorf("err = %v, want %v", err, ErrUnacceptedImageType)
+		}
+	})
+	t.Run("EnforcesMaxImageSize", func(t *testing.T) {
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = ioutil.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`{"status":"success","data":"/images/loveless.png"}`))
+		})
+		defer done()
+		c.MaxImageSize = int64(len(png)) - 1
+		c.RetryPolicy = DefaultRetryPolicy()
+
+		// the size of the reader is known so the image isn't sent at all
+		_, err := c.ChannelService.AddPicture("mbv", bytes.NewReader(png), "loveless.png", "token")
+		if !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("err = %v, want %v", err, ErrImageTooLarge)
+		}
+		// the upload is aborted once the size is exceeded
+		_, err = c.ChannelService.AddPicture("mbv", onlyReader{bytes.NewReader(png)}, "loveless.png", "token")
+		if !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("err = %v, want %v", err, ErrImageTooLarge)
+		}
+	})
+	t.Run("RetriesSeekableImages", func(t *testing.T) {
+		attempts := 0
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			f, _, err := r.FormFile("image")
+			if err != nil {
+				t.Fatalf("attempt %d: %v", attempts, err)
+			}
+			defer f.Close()
+			if got, _ := ioutil.ReadAll(f); !bytes.Equal(got, png) {
+				t.Errorf("attempt %d: image of %d bytes was received as %d bytes", attempts, len(png), len(got))
+			}
+			if attempts < 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":"/images/loveless.png"}`))
+		})
+		defer done()
+		c.RetryPolicy = DefaultRetryPolicy()
+		c.RetryPolicy.MinBackoff = time.Millisecond
+
+		url, err := c.UserService.AddPicture("loveless", bytes.NewReader(png), "loveless.png", "token")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if url != "/images/loveless.png" || attempts != 2 {
+			t.Errorf("url = %s after %d attempts", url, attempts)
+		}
+	})
+	t.Run("RetriesImagesRejectedEarly", func(t *testing.T) {
+		// the first attempt is rejected before its body is read so it's
+		// still being written when the image is seeked for the retry,
+		// which -race reports unless the retry waits for it. The
+		// rejection has a body as the transport waits for the request
+		// to be written before returning responses without one, and the
+		// image is larger than what the socket buffers hold.
+		large := append(append([]byte(nil), png[:8]...), bytes.Repeat([]byte("loveless"), 2<<20)...)
+		attempts := 0
+		c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte("overloaded"))
+				return
+			}
+			f, _, err := r.FormFile("image")
+			if err != nil {
+				t.Fatalf("attempt %d: %v", attempts, err)
+			}
+			defer f.Close()
+			if got, _ := ioutil.ReadAll(f); !bytes.Equal(got, large) {
+				t.Errorf("attempt %d: image of %d bytes was received as %d bytes", attempts, len(large), len(got))
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":"/images/loveless.png"}`))
+		})
+		defer done()
+		c.RetryPolicy = DefaultRetryPolicy()
+		c.RetryPolicy.MinBackoff = 0
+		c.RetryPolicy.Jitter = 0
+
+		url, err := c.UserService.AddPicture("loveless", bytes.NewReader(large), "loveless.png", "token")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if url != "/images/loveless.png" || attempts != 2 {
+			t.Errorf("url = %s after %d attempts", url, attempts)
+		}
+	})
+}
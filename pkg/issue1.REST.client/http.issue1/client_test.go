@@ -0,0 +1,147 @@
+package issue1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/slim-crown/issue-1-website/pkg/logging"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, func()) {
+	srv := httptest.NewServer(handler)
+	baseURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(srv.Client(), baseURL, logging.New(os.Stdout, logging.FormatLogfmt))
+	return c, srv.Close
+}
+
+func TestAPIError(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"fail","data":{"errorReason":"username","errorMessage":"username is too short"}}`))
+	})
+	defer done()
+
+	_, err := c.UserService.AddUser(&User{Username: "loveless", Email: "kevin@mbv.ie", FirstName: "Kevin", Password: "password"})
+	if !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("errors.Is(err, ErrInvalidData) = false, err = %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err is not an *APIError: %v", err)
+	}
+	want := APIError{
+		StatusCode: http.StatusBadRequest,
+		Status:     "fail",
+		Reason:     "username",
+		Message:    "username is too short",
+		Method:     http.MethodPost,
+		Path:       "/users",
+		Err:        ErrInvalidData,
+	}
+	if *apiErr != want {
+		t.Errorf("got = %+v, want %+v", *apiErr, want)
+	}
+}
+
+func TestCallObserver(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/posts/7" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":"fail","data":{"errorReason":"postID"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"username":"loveless"}}`))
+	})
+	defer done()
+
+	var endpoints []string
+	c.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			endpoints = append(endpoints, EndpointFromContext(req.Context()))
+			return next.RoundTrip(req)
+		})
+	})
+	var observed []string
+	var errs []error
+	c.CallObserver = func(endpoint string, d time.Duration, err error) {
+		observed = append(observed, endpoint)
+		errs = append(errs, err)
+	}
+
+	_, _ = c.UserService.GetUser("loveless")
+	_, _ = c.PostService.GetPost(7)
+	want := []string{"UserService.GetUser", "PostService.GetPost"}
+	if !reflect.DeepEqual(observed, want) || !reflect.DeepEqual(endpoints, want) {
+		t.Errorf("observed %v and middleware saw %v, want %v", observed, endpoints, want)
+	}
+	if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], ErrPostNotFound) {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+// TestFailureMapping checks that fail responses are mapped to the errors
+// the services returned before they were generated, e.g. any fail of
+// GetUser is ErrUserNotFound whatever its status or reason.
+func TestFailureMapping(t *testing.T) {
+	var status int
+	var reason string
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":"fail","data":{"errorReason":"` + reason + `"}}`))
+	})
+	defer done()
+	c.Logger = nil
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		reason string
+		call   func() error
+		want   error
+	}{
+		{"GetUser any fail", http.StatusBadRequest, "", func() error {
+			_, err := c.UserService.GetUser("loveless")
+			return err
+		}, ErrUserNotFound},
+		{"GetUserAuthorized unauthorized", http.StatusUnauthorized, "", func() error {
+			_, err := c.UserService.GetUserAuthorized("loveless", "token")
+			return err
+		}, ErrAccessDenied},
+		{"GetPost other reason", http.StatusNotFound, "id", func() error {
+			_, err := c.PostService.GetPost(7)
+			return err
+		}, ErrPostNotFound},
+		{"GetFeedSorting any fail", http.StatusBadRequest, "username", func() error {
+			_, err := c.FeedService.GetFeedSorting("loveless", "token")
+			return err
+		}, ErrUserNotFound},
+		{"UpdateUser any 404", http.StatusNotFound, "user", func() error {
+			_, err := c.UserService.UpdateUser("loveless", &User{}, "token")
+			return err
+		}, ErrUserNotFound},
+		{"BookmarkPost specific reason", http.StatusNotFound, "postID", func() error {
+			return c.UserService.BookmarkPost("loveless", 7, "token")
+		}, ErrPostNotFound},
+		{"BookmarkPost other reason", http.StatusNotFound, "bookmark", func() error {
+			return c.UserService.BookmarkPost("loveless", 7, "token")
+		}, ErrUserNotFound},
+		{"DeleteUser unmapped status", http.StatusNotFound, "username", func() error {
+			return c.UserService.DeleteUser("loveless", "token")
+		}, ErrRESTServerError},
+	} {
+		status, reason = tc.status, tc.reason
+		if err := tc.call(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
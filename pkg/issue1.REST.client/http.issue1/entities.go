@@ -14,7 +14,7 @@ type (
 		OwnerUsername      string    `json:"ownerUsername,omitempty"`
 		AdminUsernames     []string  `json:"adminUsernames,omitempty"`
 		PostIDs            []uint    `json:"postIDs,omitempty"`
-		StickiedPostIDs    []uint    `json:"stickiedPostIDs,omitempty "`
+		StickiedPostIDs    []uint    `json:"stickiedPostIDs,omitempty"`
 		ReleaseIDs         []uint    `json:"releaseIDs,omitempty"`
 		OfficialReleaseIDs []uint    `json:"officialReleaseIDs,omitempty"`
 		CreationTime       time.Time `json:"creationTime,omitempty"`
@@ -0,0 +1,149 @@
+package issue1
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationErrors is returned by the Validate methods of the entities. It
+// maps the fields of the JSON encoding of the entity, the same names the REST
+// server uses as the errorReason of its fail responses, to messages fit to be
+// displayed to users. It wraps ErrInvalidData.
+type ValidationErrors map[string][]string
+
+// Add adds the message to the ones of the given field.
+func (ve ValidationErrors) Add(field, message string) {
+	ve[field] = append(ve[field], message)
+}
+
+// Get returns the first message of the given field or an empty string if
+// there's none.
+func (ve ValidationErrors) Get(field string) string {
+	if len(ve[field]) == 0 {
+		return ""
+	}
+	return ve[field][0]
+}
+
+func (ve ValidationErrors) Error() string {
+	var fields []string
+	for field := range ve {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var msgs []string
+	for _, field := range fields {
+		for _, msg := range ve[field] {
+			msgs = append(msgs, field+": "+msg)
+		}
+	}
+	return fmt.Sprintf("%v (%s)", ErrInvalidData, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns ErrInvalidData.
+func (ve ValidationErrors) Unwrap() error {
+	return ErrInvalidData
+}
+
+// err returns nil if there are no errors so that an empty map isn't returned
+// as a non nil error.
+func (ve ValidationErrors) err() error {
+	if len(ve) == 0 {
+		return nil
+	}
+	return ve
+}
+
+func (ve ValidationErrors) required(field, value string) {
+	if value == "" {
+		ve.Add(field, "This field is required.")
+	}
+}
+
+func (ve ValidationErrors) length(field, value string, min, max int) {
+	if value == "" {
+		return
+	}
+	n := utf8.RuneCountInString(value)
+	if min > 0 && n < min {
+		ve.Add(field, fmt.Sprintf("This field is too short. Minimum allowed is %d characters.", min))
+	}
+	if max > 0 && n > max {
+		ve.Add(field, fmt.Sprintf("This field is too long. Maximum allowed is %d characters.", max))
+	}
+}
+
+func (ve ValidationErrors) pattern(field, value string, rx *regexp.Regexp) {
+	if value != "" && !rx.MatchString(value) {
+		ve.Add(field, "The value entered is invalid.")
+	}
+}
+
+// usernameRX matches usernames made of letters, digits and underscores
+// where underscores are neither consecutive nor trailing.
+var usernameRX = regexp.MustCompile("^[a-zA-Z]?(?:[_]?[a-zA-Z0-9])*$")
+
+var emailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+
+// Validate checks the user against the rules the REST server applies when
+// creating users. The returned error is nil or ValidationErrors.
+func (u *User) Validate() error {
+	ve := ValidationErrors{}
+	ve.required("username", u.Username)
+	ve.length("username", u.Username, 5, 24)
+	ve.pattern("username", u.Username, usernameRX)
+	ve.required("email", u.Email)
+	ve.pattern("email", u.Email, emailRX)
+	ve.required("firstName", u.FirstName)
+	ve.required("password", u.Password)
+	return ve.err()
+}
+
+// Validate checks the channel against the rules the REST server applies
+// when creating channels. The returned error is nil or ValidationErrors.
+func (c *Channel) Validate() error {
+	ve := ValidationErrors{}
+	ve.required("channelUsername", c.ChannelUsername)
+	ve.length("channelUsername", c.ChannelUsername, 0, 24)
+	ve.pattern("channelUsername", c.ChannelUsername, usernameRX)
+	return ve.err()
+}
+
+// Validate checks the post against the rules the REST server applies when
+// creating posts. The returned error is nil or ValidationErrors.
+func (p *Post) Validate() error {
+	ve := ValidationErrors{}
+	ve.required("originChannel", p.OriginChannel)
+	ve.required("title", p.Title)
+	return ve.err()
+}
+
+// Validate checks the release against the rules the REST server applies
+// when creating releases. Text releases must have content while the content
+// of image releases is set by the server from the uploaded image. The
+// returned error is nil or ValidationErrors.
+func (r *Release) Validate() error {
+	ve := ValidationErrors{}
+	ve.required("ownerChannel", r.OwnerChannel)
+	switch r.Type {
+	case Text:
+		ve.required("content", r.Content)
+	case Image:
+	case "":
+		ve.required("type", string(r.Type))
+	default:
+		ve.Add("type", fmt.Sprintf("Release type must be either %s or %s.", Image, Text))
+	}
+	return ve.err()
+}
+
+// Validate checks the comment against the rules the REST server applies
+// when creating comments. The returned error is nil or ValidationErrors.
+func (c *Comment) Validate() error {
+	ve := ValidationErrors{}
+	ve.required("content", c.Content)
+	return ve.err()
+}
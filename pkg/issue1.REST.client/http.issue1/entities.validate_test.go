@@ -0,0 +1,76 @@
+package issue1
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		entity interface{ Validate() error }
+		want   []string
+	}{
+		{"valid user", &User{Username: "loveless", Email: "kevin@mbv.ie", FirstName: "Kevin", Password: "password"}, nil},
+		{"empty user", &User{}, []string{"email", "firstName", "password", "username"}},
+		{"short username", &User{Username: "mbv", Email: "kevin@mbv.ie", FirstName: "Kevin", Password: "password"}, []string{"username"}},
+		{"malformed username", &User{Username: "love__less", Email: "kevin@mbv.ie", FirstName: "Kevin", Password: "password"}, []string{"username"}},
+		{"malformed email", &User{Username: "loveless", Email: "kevin", FirstName: "Kevin", Password: "password"}, []string{"email"}},
+		{"valid channel", &Channel{ChannelUsername: "mbv"}, nil},
+		{"malformed channel", &Channel{ChannelUsername: "my bloody valentine"}, []string{"channelUsername"}},
+		{"valid post", &Post{OriginChannel: "mbv", Title: "Only Shallow"}, nil},
+		{"untitled post", &Post{OriginChannel: "mbv"}, []string{"title"}},
+		{"valid text release", &Release{OwnerChannel: "mbv", Type: Text, Content: "Loveless"}, nil},
+		{"empty text release", &Release{OwnerChannel: "mbv", Type: Text}, []string{"content"}},
+		{"valid image release", &Release{OwnerChannel: "mbv", Type: Image}, nil},
+		{"untyped release", &Release{OwnerChannel: "mbv", Content: "Loveless"}, []string{"type"}},
+		{"unknown release type", &Release{Type: "video"}, []string{"ownerChannel", "type"}},
+		{"valid comment", &Comment{Content: "soft as a cloud"}, nil},
+		{"empty comment", &Comment{}, []string{"content"}},
+	} {
+		err := tc.entity.Validate()
+		if tc.want == nil {
+			if err != nil {
+				t.Errorf("%s: err = %v", tc.name, err)
+			}
+			continue
+		}
+		var ve ValidationErrors
+		if !errors.As(err, &ve) || !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: err = %v, wanted ValidationErrors", tc.name, err)
+			continue
+		}
+		var fields []string
+		for field := range ve {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		if !reflect.DeepEqual(fields, tc.want) {
+			t.Errorf("%s: errors on %v, wanted on %v", tc.name, fields, tc.want)
+		}
+	}
+}
+
+func TestValidateBeforeSending(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("%s %s was sent", r.Method, r.URL.Path)
+	})
+	defer done()
+
+	_, err := c.ReleaseService.AddTextRelease(&Release{OwnerChannel: "mbv"}, "token")
+	var ve ValidationErrors
+	if !errors.As(err, &ve) || ve.Get("content") == "" {
+		t.Fatalf("err = %v, wanted one on content", err)
+	}
+	if !strings.Contains(err.Error(), "content: This field is required.") {
+		t.Errorf("err = %v", err)
+	}
+	_, err = c.ReleaseService.AddImageRelease(&Release{}, strings.NewReader(jpeg), "front.jpg", "token")
+	if !errors.As(err, &ve) || ve.Get("ownerChannel") == "" {
+		t.Errorf("err = %v, wanted one on ownerChannel", err)
+	}
+}
@@ -1,37 +1,53 @@
-package issue1
+package issue1_test
 
-import "testing"
+import (
+	"testing"
 
-func TestGetFeedSorting(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
 
-	u, err := i1.FeedService.GetFeedSorting("loveless", token)
-	stdoutLogger.Printf("\nGetFeedSorting\n - - - - value:\n%+v\n\n - - - - error:\n%+v", u, err)
+func TestGetFeedSorting(t *testing.T) {
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
 
+	sorting, err := i1.FeedService.GetFeedSorting(loveless.Username, token)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if sorting != issue1.SortNew {
+		t.Errorf("wanted %s got %s", issue1.SortNew, sorting)
+	}
 }
-func TestGetFeedSubscription(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
 
-	c, err := i1.FeedService.GetFeedSubscriptions("loveless", token, SortBySubscriptionTime, "")
-	stdoutLogger.Printf("\nGetFeedSubscriptions\n - - - - value:\n%+v\n\n - - - - error:\n%+v", c, err)
+func TestGetFeedSubscription(t *testing.T) {
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
+	srv.AddChannel(issue1.Channel{ChannelUsername: "mbv", Name: "My Bloody Valentine"})
 
+	if err := i1.FeedService.SubscribeToChannel(loveless.Username, "mbv", token); err != nil {
+		t.Fatal(err)
+	}
+	c, err := i1.FeedService.GetFeedSubscriptions(loveless.Username, token, issue1.SortBySubscriptionTime, "")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(c) != 1 {
+		t.Errorf("wanted 1 subscription got %d", len(c))
+	}
 }
-func TestSetFeedSorting(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
 
-	err = i1.FeedService.SetFeedSorting(SortNew, "loveless", token)
-	stdoutLogger.Printf("\nSetFeedSorting\n - - - - error:\n%+v", err)
+func TestSetFeedSorting(t *testing.T) {
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
 
+	if err := i1.FeedService.SetFeedSorting(issue1.SortTop, loveless.Username, token); err != nil {
+		t.Fatal(err)
+	}
+	sorting, err := i1.FeedService.GetFeedSorting(loveless.Username, token)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if sorting != issue1.SortTop {
+		t.Errorf("wanted %s got %s", issue1.SortTop, sorting)
+	}
 }
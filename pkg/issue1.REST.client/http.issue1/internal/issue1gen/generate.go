@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// spec is the description of the REST endpoints found in endpoints.json.
+// Only what the generator needs is decoded.
+type spec struct {
+	Endpoints []endpoint `json:"endpoints"`
+}
+
+type endpoint struct {
+	Name     string    `json:"name"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Auth     bool      `json:"auth"`
+	Failures []failure `json:"failures"`
+	Go       goMethod  `json:"go"`
+}
+
+type failure struct {
+	Status int    `json:"status"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+// goMethod describes the method generated for an endpoint.
+type goMethod struct {
+	Doc      string          `json:"doc"`
+	Params   string          `json:"params"`
+	Returns  string          `json:"returns"`
+	Zero     string          `json:"zero"`
+	Before   string          `json:"before"`
+	Validate string          `json:"validate"`
+	Query    string          `json:"query"`
+	Body     json.RawMessage `json:"body"`
+	Image    string          `json:"image"`
+	Result   string          `json:"result"`
+}
+
+func readSpec(path string) (*spec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := new(spec)
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// method holds what the template needs to generate the methods of an
+// endpoint.
+type method struct {
+	Service, Name string
+	// Endpoint is the name of the endpoint in endpoints.json.
+	Endpoint      string
+	Doc           []string
+	Params, Args  string
+	Returns, Zero string
+	HTTPMethod    string
+	Path          string
+	Query         string
+	Token         string
+	Before        string
+	Validate      string
+	Body          string
+	Image         string
+	Failures      []string
+	// Out is the variable the data is decoded into and OutArg and
+	// OutValue are what's passed to call and returned.
+	Out, OutArg, OutValue string
+}
+
+// generate returns the generated files keyed by their names. Each service
+// gets its own file.
+func generate(s *spec) (map[string][]byte, error) {
+	services := make(map[string][]*method)
+	var order []string
+	for _, ep := range s.Endpoints {
+		m, err := newMethod(ep)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ep.Name, err)
+		}
+		if _, ok := services[m.Service]; !ok {
+			order = append(order, m.Service)
+		}
+		services[m.Service] = append(services[m.Service], m)
+	}
+
+	files := make(map[string][]byte)
+	for _, service := range order {
+		methods := services[service]
+		buf := new(bytes.Buffer)
+		err := fileTemplate.Execute(buf, struct {
+			Imports []string
+			Methods []*method
+		}{imports(methods), methods})
+		if err != nil {
+			return nil, err
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: generated invalid code: %w\n%s", service, err, buf.Bytes())
+		}
+		name := strings.ToLower(strings.TrimSuffix(service, "Service")) + ".service.gen.go"
+		files[name] = src
+	}
+	return files, nil
+}
+
+func newMethod(ep endpoint) (*method, error) {
+	dot := strings.Index(ep.Name, ".")
+	if dot < 0 {
+		return nil, fmt.Errorf("name isn't of the form Service.Method")
+	}
+	m := &method{
+		Service:  ep.Name[:dot],
+		Name:     ep.Name[dot+1:],
+		Endpoint: ep.Name,
+		Doc:      wrap(ep.Go.Doc, 76),
+		Params:   ep.Go.Params,
+		Returns:  ep.Go.Returns,
+		Before:   ep.Go.Before,
+		Validate: ep.Go.Validate,
+		Query:    ep.Go.Query,
+		Image:    ep.Go.Image,
+	}
+	if len(m.Doc) == 0 {
+		return nil, fmt.Errorf("doc is missing")
+	}
+
+	params, err := parseParams(ep.Go.Params)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, p := range params {
+		names = append(names, p.name)
+	}
+	m.Args = strings.Join(names, ", ")
+
+	if m.HTTPMethod, err = httpMethod(ep.Method); err != nil {
+		return nil, err
+	}
+	if m.Path, err = pathExpr(ep.Path, params); err != nil {
+		return nil, err
+	}
+	if ep.Auth {
+		for _, p := range params {
+			if p.name == "authToken" || p.name == "token" {
+				m.Token = p.name
+			}
+		}
+		if m.Token == "" {
+			return nil, fmt.Errorf("endpoint requires authorization but there's no authToken or token parameter")
+		}
+	}
+	if m.Body, err = bodyExpr(ep.Go.Body); err != nil {
+		return nil, err
+	}
+	for _, f := range ep.Failures {
+		m.Failures = append(m.Failures, fmt.Sprintf("{%s, %q, %s}", statusExpr(f.Status), f.Reason, f.Error))
+	}
+
+	switch {
+	case m.Returns == "":
+		m.OutArg = "nil"
+	case ep.Go.Result != "":
+		field := strings.ToUpper(ep.Go.Result[:1]) + ep.Go.Result[1:]
+		m.Out = fmt.Sprintf("var out struct {\n%s %s `json:%q`\n}", field, m.Returns, ep.Go.Result)
+		m.OutArg, m.OutValue = "&out", "out."+field
+	case strings.HasPrefix(m.Returns, "*"):
+		m.Out = fmt.Sprintf("out := new(%s)", m.Returns[1:])
+		m.OutArg, m.OutValue = "out", "out"
+	default:
+		m.Out = fmt.Sprintf("var out %s", m.Returns)
+		m.OutArg, m.OutValue = "&out", "out"
+	}
+	if m.Returns != "" {
+		if m.Zero, err = zeroValue(m.Returns, ep.Go.Zero); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+type param struct {
+	name string
+	typ  ast.Expr
+}
+
+// parseParams parses a Go parameter list.
+func parseParams(params string) ([]param, error) {
+	expr, err := parser.ParseExpr("func(" + params + ")")
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse params: %w", err)
+	}
+	var out []param
+	for _, field := range expr.(*ast.FuncType).Params.List {
+		for _, name := range field.Names {
+			out = append(out, param{name.Name, field.Type})
+		}
+	}
+	return out, nil
+}
+
+func httpMethod(method string) (string, error) {
+	for _, m := range []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	} {
+		if m == method {
+			return "http.Method" + method[:1] + strings.ToLower(method[1:]), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported method %s", method)
+}
+
+// pathExpr returns the expression building the path from the parameters
+// its :params are named after.
+func pathExpr(path string, params []param) (string, error) {
+	segments := strings.Split(path, "/")
+	var args []string
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		var p *param
+		for j := range params {
+			if params[j].name == name {
+				p = &params[j]
+			}
+		}
+		if p == nil {
+			return "", fmt.Errorf("no parameter for %s of the path", segment)
+		}
+		verb := "%v"
+		if ident, ok := p.typ.(*ast.Ident); ok {
+			switch ident.Name {
+			case "string":
+				verb = "%s"
+			case "int", "uint":
+				verb = "%d"
+			}
+		}
+		segments[i] = verb
+		args = append(args, name)
+	}
+	if len(args) == 0 {
+		return fmt.Sprintf("%q", path), nil
+	}
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", strings.Join(segments, "/"), strings.Join(args, ", ")), nil
+}
+
+// bodyExpr returns the expression of the JSON body which is either given
+// as is or as an object mapping the fields of the body to expressions.
+func bodyExpr(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	var expr string
+	if err := json.Unmarshal(raw, &expr); err == nil {
+		return expr, nil
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", fmt.Errorf("body is neither an expression nor an object of expressions")
+	}
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var elems []string
+	for _, k := range keys {
+		elems = append(elems, fmt.Sprintf("%q: %s", k, fields[k]))
+	}
+	return "map[string]interface{}{" + strings.Join(elems, ", ") + "}", nil
+}
+
+var statusNames = map[int]string{
+	0:                                "anyStatus",
+	http.StatusBadRequest:            "http.StatusBadRequest",
+	http.StatusUnauthorized:          "http.StatusUnauthorized",
+	http.StatusForbidden:             "http.StatusForbidden",
+	http.StatusNotFound:              "http.StatusNotFound",
+	http.StatusConflict:              "http.StatusConflict",
+	http.StatusRequestEntityTooLarge: "http.StatusRequestEntityTooLarge",
+	http.StatusUnsupportedMediaType:  "http.StatusUnsupportedMediaType",
+	http.StatusInternalServerError:   "http.StatusInternalServerError",
+}
+
+func statusExpr(status int) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return fmt.Sprint(status)
+}
+
+func zeroValue(typ, zero string) (string, error) {
+	switch {
+	case zero != "":
+		return zero, nil
+	case strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["):
+		return "nil", nil
+	case typ == "string":
+		return `""`, nil
+	}
+	return "", fmt.Errorf("zero value of %s must be given", typ)
+}
+
+// imports returns the packages the generated methods use.
+func imports(methods []*method) []string {
+	need := map[string]bool{"context": true, "net/http": true}
+	for _, m := range methods {
+		sig := m.Params + " " + m.Returns
+		if strings.HasPrefix(m.Path, "fmt.") {
+			need["fmt"] = true
+		}
+		if strings.Contains(sig, "io.") {
+			need["io"] = true
+		}
+		if strings.Contains(sig, "time.") {
+			need["time"] = true
+		}
+	}
+	var out []string
+	for pkg := range need {
+		out = append(out, pkg)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// wrap splits the text into lines no longer than width unless a single
+// word is.
+func wrap(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by issue1gen from endpoints.json. DO NOT EDIT.
+
+package issue1
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{range .Methods}}
+{{- range .Doc}}
+// {{.}}
+{{- end}}
+func (s *{{.Service}}) {{.Name}}({{.Params}}) {{if .Returns}}({{.Returns}}, error){{else}}error{{end}} {
+	return s.{{.Name}}Context(context.Background(), {{.Args}})
+}
+
+// {{.Name}}Context is the same as {{.Name}} but it uses the given context for the request.
+func (s *{{.Service}}) {{.Name}}Context(ctx context.Context, {{.Params}}) {{if .Returns}}({{.Returns}}, error){{else}}error{{end}} {
+	req := s.client.newRequest(ctx, {{.Path}}, {{.HTTPMethod}})
+{{- if .Query}}
+	req.URL.RawQuery = {{.Query}}.Encode()
+{{- end}}
+{{- if .Token}}
+	addJWTToRequest(req, {{.Token}})
+{{- end}}
+{{- if .Before}}
+	{{.Before}}
+{{- end}}
+{{- if .Validate}}
+	if err := {{.Validate}}.Validate(); err != nil {
+		return {{if .Returns}}{{.Zero}}, {{end}}err
+	}
+{{- end}}
+{{- if and .Body .Image}}
+	if err := s.client.addJSONAndImageToRequestAsMultipart(req, {{.Body}}, {{.Image}}); err != nil {
+		return {{if .Returns}}{{.Zero}}, {{end}}err
+	}
+{{- else if .Image}}
+	if err := s.client.addImageToRequest(req, {{.Image}}); err != nil {
+		return {{if .Returns}}{{.Zero}}, {{end}}err
+	}
+{{- else if .Body}}
+	if err := addBodyToRequestAsJSON(req, {{.Body}}); err != nil {
+		return {{if .Returns}}{{.Zero}}, {{end}}err
+	}
+{{- end}}
+{{- if .Out}}
+	{{.Out}}
+{{- end}}
+	{{if .Returns}}err := {{else}}return {{end}}s.client.call(req, "{{.Endpoint}}", {{if .Failures}}[]failure{
+	{{- range .Failures}}
+		{{.}},
+	{{- end}}
+	}{{else}}nil{{end}}, {{.OutArg}})
+{{- if .Returns}}
+	if err != nil {
+		return {{.Zero}}, err
+	}
+	return {{.OutValue}}, nil
+{{- end}}
+}
+{{end}}`))
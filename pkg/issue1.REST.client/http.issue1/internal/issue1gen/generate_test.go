@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const pkgDir = "../.."
+
+// TestGeneratedFilesUpToDate fails if endpoints.json was changed without
+// running go generate.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	s, err := readSpec(filepath.Join(pkgDir, "endpoints.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := generate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		got, err := ioutil.ReadFile(filepath.Join(pkgDir, name))
+		if err != nil {
+			t.Errorf("%s wasn't generated: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date, run go generate", name)
+		}
+	}
+}
+
+func TestNewMethod(t *testing.T) {
+	m, err := newMethod(endpoint{
+		Name:   "PostService.GetPostStar",
+		Method: "GET",
+		Path:   "/posts/:postID/stars/:username",
+		Failures: []failure{
+			{Status: 404, Reason: "postID", Error: "ErrPostNotFound"},
+			{Status: 418, Error: "ErrRESTServerError"},
+			{Error: "ErrStarNotFound"},
+		},
+		Go: goMethod{
+			Doc:     "GetPostStar returns the stars.",
+			Params:  "postID uint, username string",
+			Returns: "*Star",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `fmt.Sprintf("/posts/%d/stars/%s", postID, username)`; m.Path != want {
+		t.Errorf("path %s, wanted %s", m.Path, want)
+	}
+	if want := `{http.StatusNotFound, "postID", ErrPostNotFound}`; m.Failures[0] != want {
+		t.Errorf("failure %s, wanted %s", m.Failures[0], want)
+	}
+	if want := `{418, "", ErrRESTServerError}`; m.Failures[1] != want {
+		t.Errorf("failure %s, wanted %s", m.Failures[1], want)
+	}
+	if want := `{anyStatus, "", ErrStarNotFound}`; m.Failures[2] != want {
+		t.Errorf("failure %s, wanted %s", m.Failures[2], want)
+	}
+	if m.Zero != "nil" || m.Out != "out := new(Star)" {
+		t.Errorf("zero %s and out %s", m.Zero, m.Out)
+	}
+
+	for _, tc := range []struct {
+		name string
+		ep   endpoint
+		want string
+	}{
+		{
+			"unknown path parameter",
+			endpoint{Name: "PostService.GetPost", Method: "GET", Path: "/posts/:postID",
+				Go: goMethod{Doc: "GetPost.", Params: "id uint"}},
+			"no parameter",
+		},
+		{
+			"missing token",
+			endpoint{Name: "PostService.DeletePost", Method: "DELETE", Path: "/posts", Auth: true,
+				Go: goMethod{Doc: "DeletePost.", Params: "id uint"}},
+			"authorization",
+		},
+		{
+			"unknown zero value",
+			endpoint{Name: "FeedService.GetFeedSorting", Method: "GET", Path: "/feed",
+				Go: goMethod{Doc: "GetFeedSorting.", Returns: "FeedSorting"}},
+			"zero value",
+		},
+	} {
+		if _, err := newMethod(tc.ep); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, wanted one mentioning %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Command issue1gen generates the methods of the issue1 services from the
+description of the REST endpoints found in endpoints.json.
+
+Each endpoint of the description gets a method named after it along with its
+Context variant. The go object of an endpoint describes the method:
+
+	doc      the doc comment of the method
+	params   the parameters of the method, the ones named after the :params
+	         of the path are used to build it
+	returns  the type returned along with the error, none if omitted
+	zero     the zero value of the returned type if it isn't nil or ""
+	before   a statement run before the request is sent
+	validate the parameter whose Validate method must succeed before the
+	         request is sent
+	query    an expression of type url.Values holding the query parameters
+	body     the expression sent as the JSON body or an object mapping the
+	         fields of the body to expressions
+	image    the image and imageName parameters sent as a multipart body
+	result   the field of the data that's returned instead of the data
+
+The token of endpoints requiring authorization is taken from the parameter
+named authToken or token. Fail responses are mapped to errors according to
+the failures of the endpoints. A failure without a status matches fail
+responses of any status and one without a reason matches any errorReason.
+It's run through go generate:
+
+	go generate ./pkg/issue1.REST.client/http.issue1
+*/
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+func main() {
+	var (
+		specPath = flag.String("spec", "endpoints.json", "path of the endpoint description")
+		outDir   = flag.String("out", ".", "directory the generated files are written to")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("issue1gen: ")
+
+	s, err := readSpec(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	files, err := generate(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(*outDir, name), src, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package issue1test
+
+import (
+	"net/http"
+	"sort"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// authorizeAdmin is the same as authenticate but it also denies access if
+// the user the token belongs to isn't an admin of the channel.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request, c *issue1.Channel) bool {
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return false
+	}
+	if !contains(c.AdminUsernames, username) {
+		writeError(w, http.StatusForbidden, "user isn't an admin of "+c.ChannelUsername)
+		return false
+	}
+	return true
+}
+
+func (s *Server) postChannel(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	c := new(issue1.Channel)
+	if !decode(w, r, c) {
+		return
+	}
+	if c.ChannelUsername == "" {
+		writeFail(w, http.StatusBadRequest, "channelUsername", "channelUsername is required")
+		return
+	}
+	if _, ok := s.channels[c.ChannelUsername]; ok {
+		writeFail(w, http.StatusConflict, "channelUsername", "channelUsername is occupied")
+		return
+	}
+	c.OwnerUsername = username
+	c.AdminUsernames = nil
+	c.PostIDs, c.StickiedPostIDs = nil, nil
+	c.ReleaseIDs, c.OfficialReleaseIDs = nil, nil
+	writeSuccess(w, http.StatusCreated, s.addChannel(*c))
+}
+
+func (s *Server) getChannels(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	params := parseListParams(r)
+	channelUsernames := make([]string, 0)
+	for channelUsername, c := range s.channels {
+		if params.matches(channelUsername, c.Name, c.Description) {
+			channelUsernames = append(channelUsernames, channelUsername)
+		}
+	}
+	sort.Strings(channelUsernames)
+	from, to := params.page(len(channelUsernames))
+	channels := make([]*issue1.Channel, 0)
+	for _, channelUsername := range channelUsernames[from:to] {
+		channels = append(channels, s.channels[channelUsername])
+	}
+	writeSuccess(w, http.StatusOK, channels)
+}
+
+func (s *Server) getChannel(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	c, ok := s.channels[params["channelUsername"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "channelUsername", "channel not found")
+		return
+	}
+	writeSuccess(w, http.StatusOK, c)
+}
+
+func (s *Server) putChannel(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	c, ok := s.channels[params["channelUsername"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "channelUsername", "channel not found")
+		return
+	}
+	if !s.authorizeAdmin(w, r, c) {
+		return
+	}
+	update := new(issue1.Channel)
+	if !decode(w, r, update) {
+		return
+	}
+	if update.ChannelUsername != "" && update.ChannelUsername != c.ChannelUsername {
+		writeFail(w, http.StatusBadRequest, "channelUsername", "fake server doesn't support changing channelUsernames")
+		return
+	}
+	if update.Name != "" {
+		c.Name = update.Name
+	}
+	if update.Description != "" {
+		c.Description = update.Description
+	}
+	writeSuccess(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteChannel(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	c, ok := s.channels[params["channelUsername"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "channelUsername", "channel not found")
+		return
+	}
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	if username != c.OwnerUsername {
+		writeError(w, http.StatusForbidden, "user isn't the owner of "+c.ChannelUsername)
+		return
+	}
+	delete(s.channels, c.ChannelUsername)
+	for _, f := range s.feeds {
+		delete(f.subscriptions, c.ChannelUsername)
+	}
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) getChannelPosts(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	c, ok := s.channels[params["channelUsername"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "channelUsername", "channel not found")
+		return
+	}
+	posts := make([]*issue1.Post, 0)
+	for _, id := range sortedIDs(append([]uint(nil), c.PostIDs...)) {
+		if p, ok := s.posts[id]; ok {
+			posts = append(posts, p)
+		}
+	}
+	writeSuccess(w, http.StatusOK, posts)
+}
+
+func (s *Server) getChannelAdmins(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	c, ok := s.channels[params["channelUsername"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "channelUsername", "channel not found")
+		return
+	}
+	writeSuccess(w, http.StatusOK, c.AdminUsernames)
+}
+
+func (s *Server) getChannelOwner(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	c, ok := s.channels[params["channelUsername"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "channelUsername", "channel not found")
+		return
+	}
+	writeSuccess(w, http.StatusOK, c.OwnerUsername)
+}
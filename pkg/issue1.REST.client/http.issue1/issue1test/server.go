@@ -0,0 +1,505 @@
+/*
+Package issue1test provides an in-memory fake of the issue#1 REST server
+for use in tests. It speaks the same jSend dialect as the real server so
+both the issue1 client and the website handlers can be tested without a
+running REST server.
+
+	srv := issue1test.NewServer()
+	defer srv.Close()
+	srv.AddUser(issue1.User{Username: "loveless", Password: "password"})
+	c := srv.Client()
+	u, err := c.UserService.GetUser("loveless")
+*/
+package issue1test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// Server is a fake issue#1 REST server backed by maps. Its zero value isn't
+// usable, use NewServer.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	users     map[string]*issue1.User
+	passwords map[string]string
+	tokens    map[string]*token
+	channels  map[string]*issue1.Channel
+	posts     map[uint]*issue1.Post
+	releases  map[uint]*issue1.Release
+	comments  map[uint]*issue1.Comment
+	feeds     map[string]*feed
+	bookmarks map[string]map[uint]time.Time
+	lastID    uint
+	routes    []route
+}
+
+type token struct {
+	username string
+	// expired tokens are denied access but can still be refreshed
+	expired bool
+}
+
+type feed struct {
+	sorting       issue1.FeedSorting
+	subscriptions map[string]time.Time
+}
+
+// NewServer starts and returns a new Server. It should be closed when
+// done.
+func NewServer() *Server {
+	s := &Server{
+		users:     make(map[string]*issue1.User),
+		passwords: make(map[string]string),
+		tokens:    make(map[string]*token),
+		channels:  make(map[string]*issue1.Channel),
+		posts:     make(map[uint]*issue1.Post),
+		releases:  make(map[uint]*issue1.Release),
+		comments:  make(map[uint]*issue1.Comment),
+		feeds:     make(map[string]*feed),
+		bookmarks: make(map[string]map[uint]time.Time),
+	}
+	s.registerRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an issue1.Client that sends its requests to the Server.
+func (s *Server) Client() *issue1.Client {
+	baseURL, _ := url.Parse(s.URL)
+	return issue1.NewClient(s.Server.Client(), baseURL, nil)
+}
+
+// AddUser adds the given user to the server. Its Password is the one to be
+// used with the token-auth endpoint.
+func (s *Server) AddUser(u issue1.User) *issue1.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(u)
+}
+
+func (s *Server) addUser(u issue1.User) *issue1.User {
+	s.passwords[u.Username] = u.Password
+	u.Password = ""
+	if u.CreationTime.IsZero() {
+		u.CreationTime = time.Now()
+	}
+	s.users[u.Username] = &u
+	s.feeds[u.Username] = &feed{
+		sorting:       issue1.SortNew,
+		subscriptions: make(map[string]time.Time),
+	}
+	s.bookmarks[u.Username] = make(map[uint]time.Time)
+	return &u
+}
+
+// AddChannel adds the given channel to the server. Its owner is made an
+// admin if the AdminUsernames don't include them.
+func (s *Server) AddChannel(c issue1.Channel) *issue1.Channel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addChannel(c)
+}
+
+func (s *Server) addChannel(c issue1.Channel) *issue1.Channel {
+	if c.OwnerUsername != "" && !contains(c.AdminUsernames, c.OwnerUsername) {
+		c.AdminUsernames = append(c.AdminUsernames, c.OwnerUsername)
+	}
+	if c.CreationTime.IsZero() {
+		c.CreationTime = time.Now()
+	}
+	s.channels[c.ChannelUsername] = &c
+	return &c
+}
+
+// AddPost adds the given post to the server giving it a new ID. The post is
+// added to its OriginChannel if it exists.
+func (s *Server) AddPost(p issue1.Post) *issue1.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addPost(p)
+}
+
+func (s *Server) addPost(p issue1.Post) *issue1.Post {
+	p.ID = s.nextID()
+	if p.CreationTime.IsZero() {
+		p.CreationTime = time.Now()
+	}
+	if p.Stars == nil {
+		p.Stars = make(map[string]uint)
+	}
+	if p.ContentsID == nil {
+		p.ContentsID = make([]uint, 0)
+	}
+	if p.CommentsID == nil {
+		p.CommentsID = make([]int, 0)
+	}
+	s.posts[p.ID] = &p
+	if c, ok := s.channels[p.OriginChannel]; ok {
+		c.PostIDs = append(c.PostIDs, p.ID)
+	}
+	return &p
+}
+
+// AddRelease adds the given release to the server giving it a new ID. The
+// release is added to the catalog of its OwnerChannel if it exists.
+func (s *Server) AddRelease(r issue1.Release) *issue1.Release {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRelease(r)
+}
+
+func (s *Server) addRelease(r issue1.Release) *issue1.Release {
+	r.ID = s.nextID()
+	if r.CreationTime.IsZero() {
+		r.CreationTime = time.Now()
+	}
+	s.releases[r.ID] = &r
+	if c, ok := s.channels[r.OwnerChannel]; ok {
+		c.ReleaseIDs = append(c.ReleaseIDs, r.ID)
+	}
+	return &r
+}
+
+// AddComment adds the given comment to its OriginPost giving it a new ID.
+// Its ReplyTo should be -1 if it isn't a reply.
+func (s *Server) AddComment(c issue1.Comment) *issue1.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addComment(c)
+}
+
+func (s *Server) addComment(c issue1.Comment) *issue1.Comment {
+	c.ID = s.nextID()
+	if c.ReplyTo == 0 {
+		c.ReplyTo = -1
+	}
+	if c.CreationTime.IsZero() {
+		c.CreationTime = time.Now()
+	}
+	s.comments[c.ID] = &c
+	if p, ok := s.posts[c.OriginPost]; ok {
+		p.CommentsID = append(p.CommentsID, int(c.ID))
+	}
+	return &c
+}
+
+// Subscribe adds the channel to the feed of the user.
+func (s *Server) Subscribe(username, channelUsername string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.feeds[username]; ok {
+		f.subscriptions[channelUsername] = time.Now()
+	}
+}
+
+// Token returns a new auth token for the user under the given username
+// without going through the token-auth endpoint.
+func (s *Server) Token(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newToken(username)
+}
+
+// ExpireToken makes the server deny access to requests made with the given
+// token. It can still be refreshed using the token-auth-refresh endpoint.
+func (s *Server) ExpireToken(t string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tok, ok := s.tokens[t]; ok {
+		tok.expired = true
+	}
+}
+
+// RevokeToken makes the server deny access to requests made with the given
+// token, refreshing included.
+func (s *Server) RevokeToken(t string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, t)
+}
+
+func (s *Server) newToken(username string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	t := hex.EncodeToString(b)
+	s.tokens[t] = &token{username: username}
+	return t
+}
+
+func (s *Server) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// route maps a method and a path pattern to a handler. Segments of the
+// pattern starting with a colon match any segment and are passed to the
+// handler as parameters.
+type route struct {
+	method  string
+	pattern []string
+	handler func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+func (s *Server) handle(method, pattern string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	s.routes = append(s.routes, route{method, splitPath(pattern), handler})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := splitPath(r.URL.Path)
+	pathFound := false
+	for _, route := range s.routes {
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if route.method != r.Method {
+			continue
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		route.handler(w, r, params)
+		return
+	}
+	if pathFound {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, "endpoint not found")
+}
+
+func (rt route) match(segments []string) (map[string]string, bool) {
+	if len(rt.pattern) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, p := range rt.pattern {
+		switch {
+		case strings.HasPrefix(p, ":"):
+			params[p[1:]] = segments[i]
+		case p != segments[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+// writeSuccess writes a jSend success response holding the given data.
+func writeSuccess(w http.ResponseWriter, statusCode int, data interface{}) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+}
+
+// writeFail writes a jSend fail response.
+func writeFail(w http.ResponseWriter, statusCode int, reason, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status": "fail",
+		"data": map[string]string{
+			"errorReason":  reason,
+			"errorMessage": message,
+		},
+	})
+}
+
+// writeError writes a jSend error response.
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status":  "error",
+		"message": message,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// authenticate returns the username of the user the bearer token of the
+// request belongs to. It writes a 401 and returns false if the token isn't
+// valid.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	t := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	tok, ok := s.tokens[t]
+	if !ok || tok.expired {
+		writeError(w, http.StatusUnauthorized, "token invalid or expired")
+		return "", false
+	}
+	return tok.username, true
+}
+
+// authorize is the same as authenticate but it also denies access if the
+// token doesn't belong to the given user.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, username string) bool {
+	tokenOwner, ok := s.authenticate(w, r)
+	if !ok {
+		return false
+	}
+	if tokenOwner != username {
+		writeError(w, http.StatusUnauthorized, "token doesn't belong to "+username)
+		return false
+	}
+	return true
+}
+
+// decode decodes the JSON body of the request into v. It writes a 400 and
+// returns false if it can't.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeFail(w, http.StatusBadRequest, "request", "bad request, use well formed json")
+		return false
+	}
+	return true
+}
+
+// listParams holds the list query parameters shared by the search and list
+// endpoints.
+type listParams struct {
+	pattern       string
+	limit, offset int
+}
+
+func parseListParams(r *http.Request) listParams {
+	q := r.URL.Query()
+	p := listParams{limit: 25}
+	// the client escapes the pattern before encoding the query
+	p.pattern, _ = url.QueryUnescape(q.Get("pattern"))
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit >= 0 {
+		p.limit = limit
+	}
+	if offset, err := strconv.Atoi(q.Get("offset")); err == nil && offset >= 0 {
+		p.offset = offset
+	}
+	return p
+}
+
+// page returns the bounds of the slice of a list of n items the params
+// select.
+func (p listParams) page(n int) (from, to int) {
+	from, to = p.offset, p.offset+p.limit
+	if from > n {
+		from = n
+	}
+	if to > n {
+		to = n
+	}
+	return from, to
+}
+
+func (p listParams) matches(fields ...string) bool {
+	if p.pattern == "" {
+		return true
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), strings.ToLower(p.pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseID(w http.ResponseWriter, param, reason string) (uint, bool) {
+	id, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		writeFail(w, http.StatusBadRequest, reason, reason+" must be an unsigned integer")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func sortedIDs(ids []uint) []uint {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) registerRoutes() {
+	s.handle(http.MethodPost, "/token-auth", s.postTokenAuth)
+	s.handle(http.MethodGet, "/token-auth-refresh", s.getTokenAuthRefresh)
+	s.handle(http.MethodGet, "/logout", s.getLogout)
+
+	s.handle(http.MethodPost, "/users", s.postUser)
+	s.handle(http.MethodGet, "/users", s.getUsers)
+	s.handle(http.MethodGet, "/users/:username", s.getUser)
+	s.handle(http.MethodPut, "/users/:username", s.putUser)
+	s.handle(http.MethodDelete, "/users/:username", s.deleteUser)
+	s.handle(http.MethodGet, "/users/:username/bookmarks", s.getBookmarks)
+	s.handle(http.MethodPut, "/users/:username/bookmarks/:postID", s.putBookmark)
+	s.handle(http.MethodDelete, "/users/:username/bookmarks/:postID", s.deleteBookmark)
+	s.handle(http.MethodPut, "/users/:username/picture", s.putUserPicture)
+	s.handle(http.MethodDelete, "/users/:username/picture", s.deleteUserPicture)
+
+	s.handle(http.MethodGet, "/users/:username/feed", s.getFeed)
+	s.handle(http.MethodPut, "/users/:username/feed", s.putFeed)
+	s.handle(http.MethodGet, "/users/:username/feed/posts", s.getFeedPosts)
+	s.handle(http.MethodGet, "/users/:username/feed/channels", s.getFeedChannels)
+	s.handle(http.MethodPost, "/users/:username/feed/channels", s.postFeedChannel)
+	s.handle(http.MethodDelete, "/users/:username/feed/channels/:channelUsername", s.deleteFeedChannel)
+
+	s.handle(http.MethodPost, "/channels", s.postChannel)
+	s.handle(http.MethodGet, "/channels", s.getChannels)
+	s.handle(http.MethodGet, "/channels/:channelUsername", s.getChannel)
+	s.handle(http.MethodPut, "/channels/:channelUsername", s.putChannel)
+	s.handle(http.MethodDelete, "/channels/:channelUsername", s.deleteChannel)
+	s.handle(http.MethodGet, "/channels/:channelUsername/Posts", s.getChannelPosts)
+	s.handle(http.MethodGet, "/channels/:channelUsername/admins", s.getChannelAdmins)
+	s.handle(http.MethodGet, "/channels/:channelUsername/owners", s.getChannelOwner)
+
+	s.handle(http.MethodPost, "/posts", s.postPost)
+	s.handle(http.MethodGet, "/posts", s.getPosts)
+	s.handle(http.MethodGet, "/posts/:postID", s.getPost)
+	s.handle(http.MethodPut, "/posts/:postID", s.putPost)
+	s.handle(http.MethodDelete, "/posts/:postID", s.deletePost)
+	s.handle(http.MethodGet, "/posts/:postID/releases", s.getPostReleases)
+	s.handle(http.MethodGet, "/posts/:postID/stars", s.getPostStars)
+	s.handle(http.MethodPut, "/posts/:postID/stars", s.putPostStar)
+	s.handle(http.MethodGet, "/posts/:postID/stars/:username", s.getPostStar)
+
+	s.handle(http.MethodPost, "/posts/:postID/comments", s.postComment)
+	s.handle(http.MethodGet, "/posts/:postID/comments", s.getComments)
+	s.handle(http.MethodGet, "/posts/:postID/comments/:commentID", s.getComment)
+	s.handle(http.MethodPatch, "/posts/:postID/comments/:commentID", s.patchComment)
+	s.handle(http.MethodDelete, "/posts/:postID/comments/:commentID", s.deleteComment)
+	s.handle(http.MethodPost, "/posts/:postID/comments/:commentID/replies", s.postComment)
+	s.handle(http.MethodGet, "/posts/:postID/comments/:commentID/replies", s.getComments)
+
+	s.handle(http.MethodPost, "/releases", s.postRelease)
+	s.handle(http.MethodGet, "/releases", s.getReleases)
+	s.handle(http.MethodGet, "/releases/:releaseID", s.getRelease)
+	s.handle(http.MethodPatch, "/releases/:releaseID", s.patchRelease)
+	s.handle(http.MethodDelete, "/releases/:releaseID", s.deleteRelease)
+
+	s.handle(http.MethodGet, "/search", s.getSearch)
+}
@@ -0,0 +1,315 @@
+package issue1test
+
+import (
+	"net/http"
+	"sort"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// post returns the post under the postID param. It writes a 404 and
+// returns false if it doesn't exist.
+func (s *Server) post(w http.ResponseWriter, params map[string]string) (*issue1.Post, bool) {
+	id, ok := parseID(w, params["postID"], "postID")
+	if !ok {
+		return nil, false
+	}
+	p, ok := s.posts[id]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "postID", "post not found")
+		return nil, false
+	}
+	return p, true
+}
+
+func (s *Server) postPost(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	p := new(issue1.Post)
+	if !decode(w, r, p) {
+		return
+	}
+	c, ok := s.channels[p.OriginChannel]
+	if !ok {
+		writeFail(w, http.StatusBadRequest, "originChannel", "channel not found")
+		return
+	}
+	if !contains(c.AdminUsernames, username) {
+		writeError(w, http.StatusForbidden, "user isn't an admin of "+c.ChannelUsername)
+		return
+	}
+	p.PostedByUsername = username
+	p.Stars, p.CommentsID = nil, nil
+	writeSuccess(w, http.StatusCreated, s.addPost(*p))
+}
+
+func (s *Server) getPosts(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	params := parseListParams(r)
+	posts := make([]*issue1.Post, 0)
+	for _, p := range s.posts {
+		if params.matches(p.Title, p.Description) {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID < posts[j].ID })
+	from, to := params.page(len(posts))
+	writeSuccess(w, http.StatusOK, posts[from:to])
+}
+
+func (s *Server) getPost(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, p)
+}
+
+func (s *Server) putPost(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	if !s.authorize(w, r, p.PostedByUsername) {
+		return
+	}
+	update := new(issue1.Post)
+	if !decode(w, r, update) {
+		return
+	}
+	if update.Title != "" {
+		p.Title = update.Title
+	}
+	if update.Description != "" {
+		p.Description = update.Description
+	}
+	if update.ContentsID != nil {
+		p.ContentsID = update.ContentsID
+	}
+	writeSuccess(w, http.StatusOK, p)
+}
+
+func (s *Server) deletePost(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	if !s.authorize(w, r, p.PostedByUsername) {
+		return
+	}
+	delete(s.posts, p.ID)
+	for _, id := range p.CommentsID {
+		delete(s.comments, uint(id))
+	}
+	if c, ok := s.channels[p.OriginChannel]; ok {
+		postIDs := c.PostIDs[:0]
+		for _, id := range c.PostIDs {
+			if id != p.ID {
+				postIDs = append(postIDs, id)
+			}
+		}
+		c.PostIDs = postIDs
+	}
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) getPostReleases(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	releases := make([]*issue1.Release, 0)
+	for _, id := range p.ContentsID {
+		if rel, ok := s.releases[id]; ok {
+			releases = append(releases, rel)
+		}
+	}
+	writeSuccess(w, http.StatusOK, releases)
+}
+
+func (s *Server) getPostStars(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	usernames := make([]string, 0, len(p.Stars))
+	for username := range p.Stars {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+	stars := make([]*issue1.Star, 0, len(usernames))
+	for _, username := range usernames {
+		stars = append(stars, &issue1.Star{Username: username, NumOfStars: p.Stars[username]})
+	}
+	writeSuccess(w, http.StatusOK, stars)
+}
+
+func (s *Server) putPostStar(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	star := new(issue1.Star)
+	if !decode(w, r, star) {
+		return
+	}
+	if star.NumOfStars > 5 {
+		writeFail(w, http.StatusBadRequest, "stars", "stars must be between 0 and 5")
+		return
+	}
+	star.Username = username
+	if star.NumOfStars == 0 {
+		delete(p.Stars, username)
+	} else {
+		p.Stars[username] = star.NumOfStars
+	}
+	writeSuccess(w, http.StatusOK, star)
+}
+
+func (s *Server) getPostStar(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	stars, ok := p.Stars[params["username"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "star not found")
+		return
+	}
+	writeSuccess(w, http.StatusOK, &issue1.Star{Username: params["username"], NumOfStars: stars})
+}
+
+// comment returns the comment under the commentID param that belongs to
+// the given post. It writes a 404 and returns false if it doesn't exist.
+func (s *Server) comment(w http.ResponseWriter, p *issue1.Post, params map[string]string) (*issue1.Comment, bool) {
+	id, ok := parseID(w, params["commentID"], "commentID")
+	if !ok {
+		return nil, false
+	}
+	c, ok := s.comments[id]
+	if !ok || c.OriginPost != p.ID {
+		writeFail(w, http.StatusNotFound, "commentID", "comment not found")
+		return nil, false
+	}
+	return c, true
+}
+
+func (s *Server) postComment(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	replyTo := -1
+	if _, isReply := params["commentID"]; isReply {
+		parent, ok := s.comment(w, p, params)
+		if !ok {
+			return
+		}
+		replyTo = int(parent.ID)
+	}
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	c := new(issue1.Comment)
+	if !decode(w, r, c) {
+		return
+	}
+	if c.Content == "" {
+		writeFail(w, http.StatusBadRequest, "content", "content is required")
+		return
+	}
+	c.Commenter = username
+	c.OriginPost = p.ID
+	c.ReplyTo = replyTo
+	writeSuccess(w, http.StatusCreated, s.addComment(*c))
+}
+
+func (s *Server) getComments(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	replyTo := -1
+	if _, isReply := params["commentID"]; isReply {
+		parent, ok := s.comment(w, p, params)
+		if !ok {
+			return
+		}
+		replyTo = int(parent.ID)
+	}
+	list := parseListParams(r)
+	comments := make([]*issue1.Comment, 0)
+	for _, id := range p.CommentsID {
+		if c, ok := s.comments[uint(id)]; ok && c.ReplyTo == replyTo && list.matches(c.Content) {
+			comments = append(comments, c)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	from, to := list.page(len(comments))
+	writeSuccess(w, http.StatusOK, comments[from:to])
+}
+
+func (s *Server) getComment(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	c, ok := s.comment(w, p, params)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, c)
+}
+
+func (s *Server) patchComment(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	c, ok := s.comment(w, p, params)
+	if !ok {
+		return
+	}
+	if !s.authorize(w, r, c.Commenter) {
+		return
+	}
+	update := new(issue1.Comment)
+	if !decode(w, r, update) {
+		return
+	}
+	if update.Content == "" {
+		writeFail(w, http.StatusBadRequest, "content", "content is required")
+		return
+	}
+	c.Content = update.Content
+	writeSuccess(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	p, ok := s.post(w, params)
+	if !ok {
+		return
+	}
+	c, ok := s.comment(w, p, params)
+	if !ok {
+		return
+	}
+	if !s.authorize(w, r, c.Commenter) {
+		return
+	}
+	delete(s.comments, c.ID)
+	commentsID := p.CommentsID[:0]
+	for _, id := range p.CommentsID {
+		if uint(id) != c.ID {
+			commentsID = append(commentsID, id)
+		}
+	}
+	p.CommentsID = commentsID
+	writeSuccess(w, http.StatusOK, nil)
+}
@@ -0,0 +1,187 @@
+package issue1test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+// decodeRelease decodes the release in the request which is either sent as
+// JSON or, for image releases, as a multipart form holding a "JSON" part and
+// an "image" part. The Content of image releases is set to the URL of the
+// uploaded image.
+func decodeRelease(w http.ResponseWriter, r *http.Request) (*issue1.Release, bool) {
+	rel := new(issue1.Release)
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return rel, decode(w, r, rel)
+	}
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		writeFail(w, http.StatusBadRequest, "request", "bad request, unable to parse multipart form")
+		return nil, false
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("JSON")), rel); err != nil {
+		writeFail(w, http.StatusBadRequest, "request", "bad request, use well formed json")
+		return nil, false
+	}
+	_, header, err := r.FormFile("image")
+	if err != nil {
+		writeFail(w, http.StatusBadRequest, "image", "unable to read image")
+		return nil, false
+	}
+	rel.Content = "/images/" + header.Filename
+	return rel, true
+}
+
+// release returns the release under the releaseID param. It writes a 404
+// and returns false if it doesn't exist.
+func (s *Server) release(w http.ResponseWriter, params map[string]string) (*issue1.Release, bool) {
+	id, ok := parseID(w, params["releaseID"], "releaseID")
+	if !ok {
+		return nil, false
+	}
+	rel, ok := s.releases[id]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "releaseID", "release not found")
+		return nil, false
+	}
+	return rel, true
+}
+
+func (s *Server) postRelease(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+	rel, ok := decodeRelease(w, r)
+	if !ok {
+		return
+	}
+	c, ok := s.channels[rel.OwnerChannel]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "ownerChannel", "channel not found")
+		return
+	}
+	if !s.authorizeAdmin(w, r, c) {
+		return
+	}
+	writeSuccess(w, http.StatusCreated, s.addRelease(*rel))
+}
+
+func (s *Server) getReleases(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	params := parseListParams(r)
+	releases := make([]*issue1.Release, 0)
+	for _, rel := range s.releases {
+		if params.matches(rel.Title, rel.Description, rel.GenreDefining) {
+			releases = append(releases, rel)
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].ID < releases[j].ID })
+	from, to := params.page(len(releases))
+	writeSuccess(w, http.StatusOK, releases[from:to])
+}
+
+func (s *Server) getRelease(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	rel, ok := s.release(w, params)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, rel)
+}
+
+func (s *Server) patchRelease(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	rel, ok := s.release(w, params)
+	if !ok {
+		return
+	}
+	if c, ok := s.channels[rel.OwnerChannel]; ok && !s.authorizeAdmin(w, r, c) {
+		return
+	}
+	update, ok := decodeRelease(w, r)
+	if !ok {
+		return
+	}
+	if update.Content != "" {
+		rel.Content = update.Content
+	}
+	if update.Title != "" {
+		rel.Title = update.Title
+	}
+	if update.Description != "" {
+		rel.Description = update.Description
+	}
+	if update.GenreDefining != "" {
+		rel.GenreDefining = update.GenreDefining
+	}
+	writeSuccess(w, http.StatusOK, rel)
+}
+
+func (s *Server) deleteRelease(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	rel, ok := s.release(w, params)
+	if !ok {
+		return
+	}
+	if c, ok := s.channels[rel.OwnerChannel]; ok {
+		if !s.authorizeAdmin(w, r, c) {
+			return
+		}
+		releaseIDs := c.ReleaseIDs[:0]
+		for _, id := range c.ReleaseIDs {
+			if id != rel.ID {
+				releaseIDs = append(releaseIDs, id)
+			}
+		}
+		c.ReleaseIDs = releaseIDs
+	}
+	delete(s.releases, rel.ID)
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) getSearch(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	params := parseListParams(r)
+	results := issue1.SearchResults{
+		Posts:    make([]*issue1.Post, 0),
+		Releases: make([]*issue1.Release, 0),
+		Comments: make([]*issue1.Comment, 0),
+		Channels: make([]*issue1.Channel, 0),
+		Users:    make([]*issue1.User, 0),
+	}
+	if params.pattern == "" {
+		writeSuccess(w, http.StatusOK, results)
+		return
+	}
+	for _, p := range s.posts {
+		if params.matches(p.Title, p.Description) {
+			results.Posts = append(results.Posts, p)
+		}
+	}
+	sort.Slice(results.Posts, func(i, j int) bool { return results.Posts[i].ID < results.Posts[j].ID })
+	for _, rel := range s.releases {
+		if params.matches(rel.Title, rel.Description) {
+			results.Releases = append(results.Releases, rel)
+		}
+	}
+	sort.Slice(results.Releases, func(i, j int) bool { return results.Releases[i].ID < results.Releases[j].ID })
+	for _, c := range s.comments {
+		if params.matches(c.Content) {
+			results.Comments = append(results.Comments, c)
+		}
+	}
+	sort.Slice(results.Comments, func(i, j int) bool { return results.Comments[i].ID < results.Comments[j].ID })
+	for _, c := range s.channels {
+		if params.matches(c.ChannelUsername, c.Name, c.Description) {
+			results.Channels = append(results.Channels, c)
+		}
+	}
+	sort.Slice(results.Channels, func(i, j int) bool {
+		return results.Channels[i].ChannelUsername < results.Channels[j].ChannelUsername
+	})
+	for _, u := range s.users {
+		if params.matches(u.Username, u.FirstName, u.MiddleName, u.LastName, u.Bio) {
+			results.Users = append(results.Users, s.publicUser(r, u))
+		}
+	}
+	sort.Slice(results.Users, func(i, j int) bool { return results.Users[i].Username < results.Users[j].Username })
+	writeSuccess(w, http.StatusOK, results)
+}
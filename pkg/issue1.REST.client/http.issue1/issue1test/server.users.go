@@ -0,0 +1,390 @@
+package issue1test
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+func (s *Server) postTokenAuth(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var credentials struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if !decode(w, r, &credentials) {
+		return
+	}
+	password, ok := s.passwords[credentials.Username]
+	if !ok || password != credentials.Password {
+		writeError(w, http.StatusUnauthorized, "credentials not accepted")
+		return
+	}
+	writeSuccess(w, http.StatusOK, map[string]string{"token": s.newToken(credentials.Username)})
+}
+
+func (s *Server) getTokenAuthRefresh(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	t := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	tok, ok := s.tokens[t]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "token invalid")
+		return
+	}
+	delete(s.tokens, t)
+	writeSuccess(w, http.StatusOK, map[string]string{"token": s.newToken(tok.username)})
+}
+
+func (s *Server) getLogout(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+	delete(s.tokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+// publicUser returns a copy of the user without the private information
+// unless the request is authorized as the user.
+func (s *Server) publicUser(r *http.Request, u *issue1.User) *issue1.User {
+	public := *u
+	t := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if tok, ok := s.tokens[t]; !ok || tok.expired || tok.username != u.Username {
+		public.Email = ""
+	}
+	return &public
+}
+
+func (s *Server) postUser(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	u := new(issue1.User)
+	if !decode(w, r, u) {
+		return
+	}
+	switch {
+	case u.Username == "":
+		writeFail(w, http.StatusBadRequest, "username", "username is required")
+		return
+	case u.Password == "":
+		writeFail(w, http.StatusBadRequest, "password", "password is required")
+		return
+	}
+	if _, ok := s.users[u.Username]; ok {
+		writeFail(w, http.StatusConflict, "username", "username is occupied")
+		return
+	}
+	for _, other := range s.users {
+		if u.Email != "" && other.Email == u.Email {
+			writeFail(w, http.StatusConflict, "email", "email is occupied")
+			return
+		}
+	}
+	writeSuccess(w, http.StatusCreated, s.addUser(*u))
+}
+
+func (s *Server) getUsers(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	params := parseListParams(r)
+	usernames := make([]string, 0)
+	for username, u := range s.users {
+		if params.matches(username, u.FirstName, u.MiddleName, u.LastName, u.Bio) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	from, to := params.page(len(usernames))
+	users := make([]*issue1.User, 0)
+	for _, username := range usernames[from:to] {
+		users = append(users, s.publicUser(r, s.users[username]))
+	}
+	writeSuccess(w, http.StatusOK, users)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	u, ok := s.users[params["username"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	writeSuccess(w, http.StatusOK, s.publicUser(r, u))
+}
+
+func (s *Server) putUser(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	u, ok := s.users[params["username"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, u.Username) {
+		return
+	}
+	update := new(issue1.User)
+	if !decode(w, r, update) {
+		return
+	}
+	if update.Username != "" && update.Username != u.Username {
+		writeFail(w, http.StatusBadRequest, "username", "fake server doesn't support changing usernames")
+		return
+	}
+	for field, value := range map[*string]string{
+		&u.Email:      update.Email,
+		&u.FirstName:  update.FirstName,
+		&u.MiddleName: update.MiddleName,
+		&u.LastName:   update.LastName,
+		&u.Bio:        update.Bio,
+	} {
+		if value != "" {
+			*field = value
+		}
+	}
+	if update.Password != "" {
+		s.passwords[u.Username] = update.Password
+	}
+	writeSuccess(w, http.StatusOK, u)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	if _, ok := s.users[username]; !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	delete(s.users, username)
+	delete(s.passwords, username)
+	delete(s.feeds, username)
+	delete(s.bookmarks, username)
+	for t, tok := range s.tokens {
+		if tok.username == username {
+			delete(s.tokens, t)
+		}
+	}
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) getBookmarks(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	if !s.authorize(w, r, username) {
+		return
+	}
+	bookmarks := make(map[time.Time]*issue1.Post)
+	for postID, t := range s.bookmarks[username] {
+		if p, ok := s.posts[postID]; ok {
+			bookmarks[t] = p
+		}
+	}
+	writeSuccess(w, http.StatusOK, bookmarks)
+}
+
+func (s *Server) putBookmark(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	if _, ok := s.users[username]; !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	postID, ok := parseID(w, params["postID"], "postID")
+	if !ok {
+		return
+	}
+	if _, ok := s.posts[postID]; !ok {
+		writeFail(w, http.StatusNotFound, "postID", "post not found")
+		return
+	}
+	s.bookmarks[username][postID] = time.Now()
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) deleteBookmark(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	if _, ok := s.users[username]; !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	postID, ok := parseID(w, params["postID"], "postID")
+	if !ok {
+		return
+	}
+	delete(s.bookmarks[username], postID)
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) putUserPicture(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	u, ok := s.users[params["username"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, u.Username) {
+		return
+	}
+	_, header, err := r.FormFile("image")
+	if err != nil {
+		writeFail(w, http.StatusBadRequest, "image", "unable to read image")
+		return
+	}
+	u.PictureURL = "/images/" + header.Filename
+	writeSuccess(w, http.StatusOK, u.PictureURL)
+}
+
+func (s *Server) deleteUserPicture(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	u, ok := s.users[params["username"]]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, u.Username) {
+		return
+	}
+	u.PictureURL = ""
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) getFeed(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	f, ok := s.feeds[username]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	writeSuccess(w, http.StatusOK, issue1.Feed{OwnerUsername: username, Sorting: f.sorting})
+}
+
+func (s *Server) putFeed(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	f, ok := s.feeds[username]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	var update struct {
+		Sorting issue1.FeedSorting `json:"defaultSorting"`
+	}
+	if !decode(w, r, &update) {
+		return
+	}
+	switch update.Sorting {
+	case issue1.SortNew, issue1.SortTop, issue1.SortHot:
+		f.sorting = update.Sorting
+	default:
+		writeFail(w, http.StatusBadRequest, "defaultSorting", "unrecognized sorting")
+		return
+	}
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) getFeedPosts(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	f, ok := s.feeds[username]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	posts := make([]*issue1.Post, 0)
+	for _, p := range s.posts {
+		if _, subscribed := f.subscriptions[p.OriginChannel]; subscribed {
+			posts = append(posts, p)
+		}
+	}
+	sorting := issue1.FeedSorting(r.URL.Query().Get("sort"))
+	if sorting == issue1.NotSet {
+		sorting = f.sorting
+	}
+	sortPosts(posts, sorting)
+	from, to := parseListParams(r).page(len(posts))
+	writeSuccess(w, http.StatusOK, posts[from:to])
+}
+
+func sortPosts(posts []*issue1.Post, sorting issue1.FeedSorting) {
+	key := func(p *issue1.Post) int {
+		switch sorting {
+		case issue1.SortTop:
+			count := 0
+			for _, stars := range p.Stars {
+				count += int(stars)
+			}
+			return count
+		case issue1.SortHot:
+			return len(p.CommentsID)
+		default:
+			return int(p.ID)
+		}
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		if ki, kj := key(posts[i]), key(posts[j]); ki != kj {
+			return ki > kj
+		}
+		return posts[i].ID > posts[j].ID
+	})
+}
+
+func (s *Server) getFeedChannels(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	f, ok := s.feeds[username]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	channels := make(map[time.Time]*issue1.Channel)
+	for channelUsername, t := range f.subscriptions {
+		if c, ok := s.channels[channelUsername]; ok {
+			channels[t] = c
+		}
+	}
+	writeSuccess(w, http.StatusOK, channels)
+}
+
+func (s *Server) postFeedChannel(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	f, ok := s.feeds[username]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	var subscription struct {
+		Channelname string `json:"channelname"`
+	}
+	if !decode(w, r, &subscription) {
+		return
+	}
+	if _, ok := s.channels[subscription.Channelname]; !ok {
+		writeFail(w, http.StatusNotFound, "channelname", "channel not found")
+		return
+	}
+	f.subscriptions[subscription.Channelname] = time.Now()
+	writeSuccess(w, http.StatusOK, nil)
+}
+
+func (s *Server) deleteFeedChannel(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	username := params["username"]
+	f, ok := s.feeds[username]
+	if !ok {
+		writeFail(w, http.StatusNotFound, "username", "user not found")
+		return
+	}
+	if !s.authorize(w, r, username) {
+		return
+	}
+	delete(f.subscriptions, params["channelUsername"])
+	writeSuccess(w, http.StatusOK, nil)
+}
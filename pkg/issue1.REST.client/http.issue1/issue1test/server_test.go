@@ -0,0 +1,102 @@
+package issue1test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+)
+
+func TestServerRouting(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/users", http.StatusOK},
+		{http.MethodPatch, "/users", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/nowhere", http.StatusNotFound},
+		{http.MethodGet, "/posts/notanid", http.StatusBadRequest},
+	} {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+		resp, err := srv.Server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s returned %d, wanted %d", tc.method, tc.path, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestServerTokens(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddUser(issue1.User{Username: "loveless", Password: "password"})
+	c := srv.Client()
+
+	if _, err := c.GetAuthToken("loveless", "wrong"); !errors.Is(err, issue1.ErrCredentialsUnaccepted) {
+		t.Errorf("GetAuthToken() with a wrong password returned error %v", err)
+	}
+
+	token := srv.Token("loveless")
+	srv.ExpireToken(token)
+	if _, err := c.UserService.GetUserBookmarks("loveless", token); !errors.Is(err, issue1.ErrAccessDenied) {
+		t.Errorf("expired token wasn't denied access, error %v", err)
+	}
+	refreshed, err := c.RefreshAuthToken(token)
+	if err != nil {
+		t.Fatalf("RefreshAuthToken() returned error %s", err)
+	}
+	if _, err := c.UserService.GetUserBookmarks("loveless", refreshed); err != nil {
+		t.Errorf("refreshed token was denied access, error %v", err)
+	}
+
+	srv.RevokeToken(refreshed)
+	if _, err := c.RefreshAuthToken(refreshed); !errors.Is(err, issue1.ErrAccessDenied) {
+		t.Errorf("RefreshAuthToken() of a revoked token returned error %v", err)
+	}
+}
+
+func TestServerPostsAndComments(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddUser(issue1.User{Username: "loveless", Password: "password"})
+	srv.AddChannel(issue1.Channel{ChannelUsername: "mbv", OwnerUsername: "loveless"})
+	c := srv.Client()
+	token := srv.Token("loveless")
+
+	p, err := c.PostService.AddPost(&issue1.Post{OriginChannel: "mbv", Title: "Only Shallow"}, token)
+	if err != nil {
+		t.Fatalf("AddPost() returned error %s", err)
+	}
+	if p.PostedByUsername != "loveless" {
+		t.Errorf("post wasn't attributed to its poster: %+v", p)
+	}
+	comment, err := c.CommentService.AddComment(p.ID, &issue1.Comment{Content: "soft as a cloud"}, token)
+	if err != nil {
+		t.Fatalf("AddComment() returned error %s", err)
+	}
+	if _, err := c.CommentService.AddReply(comment.ID, p.ID, &issue1.Comment{Content: "sharp as a knife"}, token); err != nil {
+		t.Fatalf("AddReply() returned error %s", err)
+	}
+	comments, err := c.CommentService.GetComments(p.ID, issue1.SortCommentsByCreationTime, issue1.PaginateParams{})
+	if err != nil {
+		t.Fatalf("GetComments() returned error %s", err)
+	}
+	if len(comments) != 1 {
+		t.Errorf("replies were listed with the comments: %+v", comments)
+	}
+
+	results, err := c.SearchService.Search("shallow", issue1.SortByRank, issue1.PaginateParams{})
+	if err != nil {
+		t.Fatalf("Search() returned error %s", err)
+	}
+	if len(results.Posts) != 1 || results.Posts[0].ID != p.ID {
+		t.Errorf("Search() didn't find the post: %+v", results.Posts)
+	}
+}
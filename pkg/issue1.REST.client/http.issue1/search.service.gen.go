@@ -0,0 +1,27 @@
+// Code generated by issue1gen from endpoints.json. DO NOT EDIT.
+
+package issue1
+
+import (
+	"context"
+	"net/http"
+)
+
+// Search returns the posts, releases, comments, channels and users matching
+// the given pattern. If any of the fields on the passed in PaginateParams are
+// omitted, it'll use the default values.
+func (s *SearchService) Search(pattern string, by SortResultsBy, params PaginateParams) (*SearchResults, error) {
+	return s.SearchContext(context.Background(), pattern, by, params)
+}
+
+// SearchContext is the same as Search but it uses the given context for the request.
+func (s *SearchService) SearchContext(ctx context.Context, pattern string, by SortResultsBy, params PaginateParams) (*SearchResults, error) {
+	req := s.client.newRequest(ctx, "/search", http.MethodGet)
+	req.URL.RawQuery = searchQuery(pattern, string(by), params).Encode()
+	out := new(SearchResults)
+	err := s.client.call(req, "SearchService.Search", nil, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
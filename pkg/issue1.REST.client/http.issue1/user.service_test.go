@@ -1,177 +1,203 @@
-package issue1
+package issue1_test
 
 import (
-	"github.com/slim-crown/issue-1-website/internal/delivery/web"
-	"os"
+	"bytes"
+	"errors"
 	"testing"
 	"time"
+
+	issue1 "github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1"
+	"github.com/slim-crown/issue-1-website/pkg/issue1.REST.client/http.issue1/issue1test"
 )
 
-var aUser = User{"slimmy", "miku@miku.com", "mikee", "boy", "shifi",
-	time.Now(), "like you don't know", "addis", "abc123"}
+var aUser = issue1.User{
+	Username:     "slimmy",
+	Email:        "miku@miku.com",
+	FirstName:    "mikee",
+	MiddleName:   "boy",
+	LastName:     "shifi",
+	CreationTime: time.Now(),
+	Bio:          "like you don't know",
+	Password:     "abc123",
+}
+
+var loveless = issue1.User{
+	Username:   "loveless",
+	Email:      "stars@destination.com",
+	FirstName:  "Jeff",
+	MiddleName: "k.",
+	LastName:   "Shoes",
+	Bio:        "i don't know what's real",
+	Password:   "password",
+}
 
-var s = web.Setup{}
-var i1 = s.Iss1C
-var stdoutLogger = s.Logger
-var defaultUsername = "slimmy"
+// newFakeServer starts a fake REST server seeded with loveless and returns
+// it along with a client for it and an auth token for loveless.
+func newFakeServer(t *testing.T) (*issue1test.Server, *issue1.Client, string) {
+	srv := issue1test.NewServer()
+	srv.AddUser(loveless)
+	i1 := srv.Client()
+	token, err := i1.GetAuthToken(loveless.Username, loveless.Password)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("GetAuthToken() returned error %s", err)
+	}
+	return srv, i1, token
+}
 
 func TestAddUser(t *testing.T) {
-	us1 := UserService{}
-	wantResult := "slimmy"
-	gotResult, err := us1.AddUser(&aUser)
+	srv, i1, _ := newFakeServer(t)
+	defer srv.Close()
 
+	u := aUser
+	gotResult, err := i1.UserService.AddUser(&u)
 	if err != nil {
-		t.Errorf("AddUser() returned error %s", err)
+		t.Fatalf("AddUser() returned error %s", err)
+	}
+	if gotResult.Username != aUser.Username {
+		t.Errorf("wanted %s got %s", aUser.Username, gotResult.Username)
 	}
 
-	if wantResult != gotResult.Username {
-		t.Errorf("Wrong result recevied")
+	_, err = i1.UserService.AddUser(&u)
+	if !errors.Is(err, issue1.ErrUserNameOccupied) {
+		t.Errorf("adding a user twice returned error %v, wanted %v", err, issue1.ErrUserNameOccupied)
 	}
 }
 
 func TestGetUser(t *testing.T) {
-	us1 := UserService{}
-	value := "slimmy"
-	gotResult, err := us1.GetUser(value)
+	srv, i1, _ := newFakeServer(t)
+	defer srv.Close()
 
+	gotResult, err := i1.UserService.GetUser(loveless.Username)
 	if err != nil {
-		t.Errorf("GetUser() returned error %s", err)
+		t.Fatalf("GetUser() returned error %s", err)
+	}
+	if gotResult.Username != loveless.Username {
+		t.Errorf("wanted %s got %+v", loveless.Username, gotResult)
 	}
 
-	if value != gotResult.Username {
-		t.Errorf("wanted %  got %", value, gotResult)
+	_, err = i1.UserService.GetUser("nobody")
+	if !errors.Is(err, issue1.ErrUserNotFound) {
+		t.Errorf("GetUser() of a missing user returned error %v, wanted %v", err, issue1.ErrUserNotFound)
 	}
 }
 
-/*
-	u, err := i1.UserService.GetUser("slimmyNewNewNew")
-	stdoutLogger.Printf("\nGetUser\n - - - - value:\n%+v\n\n - - - - error:\n%+v", u, err)
-*/
-/*
-	u, err := i1.UserService.AddUser(&issue1.User{
-		Username:   "loveless",
-		Email:      "stars@destination.com",
-		FirstName:  "Jeff",
-		MiddleName: "k.",
-		LastName:   "Shoes",
-		Bio:        "i don't know what's real",
-		Password:   "password",
-	})
-	stdoutLogger.Printf("\nAddUser\n - - - - value:\n%+v\n\n - - - - error:\n%+v", u, err)
-*/
-
 func TestSearchUsers(t *testing.T) {
-	var users []*User
-	users, err := i1.UserService.SearchUsers(defaultUsername, SortUsersByUsername, PaginateParams{
-		SortDescending,
-		0,
-		0,
-	})
-
-	stdoutLogger.Printf("\nSearchUser\n - - - - value:\n%#v\n\n - - - - error:\n%+v", users, err)
-	if err == nil {
-		for _, u := range users {
-			stdoutLogger.Printf("%v\n", u)
+	srv, i1, _ := newFakeServer(t)
+	defer srv.Close()
+	srv.AddUser(aUser)
 
-		}
-	}
+	users, err := i1.UserService.SearchUsers("", issue1.SortUsersByUsername, issue1.PaginateParams{
+		SortOrder: issue1.SortDescending,
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	/*var s []string
-	var s2 *User
-	for _, v:=range users{
-		//s=append(s,v)
-		s2+=v
+	if len(users) != 2 {
+		t.Fatalf("wanted 2 users got %d", len(users))
 	}
-	if s[0] !=  aUser.Username{}
-	*/
 
+	users, err = i1.UserService.SearchUsers("slim", issue1.SortUsersByUsername, issue1.PaginateParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Username != aUser.Username {
+		t.Errorf("wanted only %s got %+v", aUser.Username, users)
+	}
 }
 
-/*
-	token, err := i1.GetAuthToken("loveless","password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
-
-	err = i1.Logout(token)
-	stdoutLogger.Printf("\nLogout\n - - - - error:\n%+v", err)
-
-	refreshedToken, err := i1.RefreshAuthToken(token)
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", refreshedToken, err)
-
-*/
 func TestUpdateUser(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
 
-	gotValue, err := i1.UserService.UpdateUser("loveless", &aUser, token)
-	stdoutLogger.Printf("\nUpdateUser\n - - - - value:\n%+v\n\n - - - - error:\n%+v", gotValue, err)
-	if gotValue.Username != "loveless" {
-		t.Errorf("wanted value is 'loveless' got value is %s", gotValue.Username)
-	}
+	gotValue, err := i1.UserService.UpdateUser(loveless.Username, &issue1.User{Bio: "only shallow"}, token)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if gotValue.Username != loveless.Username {
+		t.Errorf("wanted value is %s got value is %s", loveless.Username, gotValue.Username)
+	}
+	if gotValue.Bio != "only shallow" {
+		t.Errorf("bio wasn't updated: %+v", gotValue)
+	}
 }
-func TestDeleteUser(t *testing.T) {
-	u, err := i1.UserService.AddUser(&aUser)
-	stdoutLogger.Printf("\nAddUser\n - - - - value:\n%+v\n\n - - - - error:\n%+v", u, err)
-
-	token, err := i1.GetAuthToken("slimmy", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
 
-	err = i1.UserService.DeleteUser("slimmy", token)
-	stdoutLogger.Printf("\nDeleteUser\n - - - - error:\n%+v", err)
+func TestDeleteUser(t *testing.T) {
+	srv, i1, _ := newFakeServer(t)
+	defer srv.Close()
 
+	u := aUser
+	if _, err := i1.UserService.AddUser(&u); err != nil {
+		t.Fatal(err)
+	}
+	token, err := i1.GetAuthToken(aUser.Username, aUser.Password)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err = i1.UserService.DeleteUser(aUser.Username, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = i1.UserService.GetUser(aUser.Username); !errors.Is(err, issue1.ErrUserNotFound) {
+		t.Errorf("GetUser() of the deleted user returned error %v, wanted %v", err, issue1.ErrUserNotFound)
+	}
 }
 
 func TestBookmarkPost(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
-
-	err = i1.UserService.BookmarkPost("loveless", 3, token)
-	stdoutLogger.Printf("\nBookmarkPost\n - - - - error:\n%+v", err)
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
+	p := srv.AddPost(issue1.Post{Title: "Only Shallow"})
 
+	if err := i1.UserService.BookmarkPost(loveless.Username, int(p.ID), token); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err := i1.UserService.GetUserBookmarks(loveless.Username, token)
 	if err != nil {
 		t.Fatal(err)
 	}
-
+	if len(bookmarks) != 1 {
+		t.Errorf("wanted 1 bookmark got %d", len(bookmarks))
+	}
 }
-func TestDeleteBookmark(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
 
-	err = i1.UserService.DeleteBookmark("loveless", 3, token)
-	stdoutLogger.Printf("\nDeleteBookmark\n - - - - error:\n%+v", err)
+func TestDeleteBookmark(t *testing.T) {
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
+	p := srv.AddPost(issue1.Post{Title: "Only Shallow"})
 
+	if err := i1.UserService.BookmarkPost(loveless.Username, int(p.ID), token); err != nil {
+		t.Fatal(err)
+	}
+	if err := i1.UserService.DeleteBookmark(loveless.Username, int(p.ID), token); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err := i1.UserService.GetUserBookmarks(loveless.Username, token)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(bookmarks) != 0 {
+		t.Errorf("wanted no bookmarks got %d", len(bookmarks))
+	}
 }
 
 func TestAddPicture(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	stdoutLogger.Printf("\nGetAuthToken\n - - - - value:\n%#v\n\n - - - - error:\n%+v", token, err)
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
 
-	image, err := os.Open("E:\\Files\\MuSec\\Alternative\\! My Bloody Valentine\\My Bloody Valentine [2008] Loveless\\front.jpg")
+	image := bytes.NewReader([]byte("\xff\xd8\xff\xe0 not quite a jpeg"))
+	path, err := i1.UserService.AddPicture(loveless.Username, image, "lovelessness.jpg", token)
 	if err != nil {
-		stdoutLogger.Printf("hmm...error: %+v\n", err)
-		panic(err)
+		t.Fatal(err)
+	}
+	if path == "" {
+		t.Errorf("AddPicture() returned an empty path")
 	}
-	defer image.Close()
-
-	path, err := i1.UserService.AddPicture("loveless", image, "lovelessness.jpg", token)
-	stdoutLogger.Printf("\nAddPicture\n - - - - value:\n%s\n\n - - - - error:\n%+v", path, err)
 }
 
 func TestRemovePicture(t *testing.T) {
-	token, err := i1.GetAuthToken("loveless", "password")
-	err = i1.UserService.RemovePicture("loveless", token)
-	stdoutLogger.Printf("\nRemovePicture\n - - - - error:\n%+v", err)
-	if err != nil {
+	srv, i1, token := newFakeServer(t)
+	defer srv.Close()
+
+	if err := i1.UserService.RemovePicture(loveless.Username, token); err != nil {
 		t.Fatal(err)
 	}
 }
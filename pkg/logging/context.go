@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"context"
+	"sync"
+)
+
+type loggerKey struct{}
+
+// holder lets the fields of the logger carried by a context be added to
+// after the context is created, e.g. once the user of a request is known.
+type holder struct {
+	mu     sync.Mutex
+	logger *Logger
+}
+
+// NewContext returns a copy of ctx carrying the given logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &holder{logger: l})
+}
+
+// FromContext returns the logger carried by ctx, nil if there's none.
+func FromContext(ctx context.Context) *Logger {
+	h, ok := ctx.Value(loggerKey{}).(*holder)
+	if !ok {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.logger
+}
+
+// AddFields adds the given fields to the logger carried by ctx. It does
+// nothing if ctx carries none.
+func AddFields(ctx context.Context, fields ...Field) {
+	h, ok := ctx.Value(loggerKey{}).(*holder)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logger = h.logger.With(fields...)
+}
@@ -0,0 +1,306 @@
+// Package logging provides a structured, leveled logger writing entries as
+// JSON or logfmt. Loggers are split into components, e.g. "web" or "issue1",
+// whose levels can be set independently.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of an entry.
+type Level int
+
+// The levels of entries from the least to the most severe.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level of the given name, e.g. "warn".
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("logging: unknown level %q", name)
+}
+
+// Format is the encoding of the entries.
+type Format int
+
+// The formats entries can be written in.
+const (
+	FormatLogfmt Format = iota
+	FormatJSON
+)
+
+// ParseFormat returns the format of the given name, "logfmt" or "json".
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "logfmt":
+		return FormatLogfmt, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return 0, fmt.Errorf("logging: unknown format %q", name)
+}
+
+// Field is a key-value pair added to entries.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F returns a Field.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns a Field holding err under the "error" key.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// output is shared by a logger and all the loggers derived from it.
+type output struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Format
+	level  Level
+	levels map[string]Level
+	now    func() time.Time
+}
+
+// Logger writes structured entries. The loggers returned by its methods
+// share its writer and levels. Its methods are safe to call on a nil Logger
+// which discards everything.
+type Logger struct {
+	out       *output
+	component string
+	fields    []Field
+}
+
+// New returns a logger writing entries of level LevelInfo and above to w in
+// the given format.
+func New(w io.Writer, format Format) *Logger {
+	return &Logger{out: &output{
+		w:      w,
+		format: format,
+		level:  LevelInfo,
+		levels: make(map[string]Level),
+		now:    time.Now,
+	}}
+}
+
+// Component returns a logger for the named component. Its entries are
+// tagged with the component and filtered by the level set for it.
+func (l *Logger) Component(name string) *Logger {
+	if l == nil {
+		return nil
+	}
+	return &Logger{out: l.out, component: name, fields: l.fields}
+}
+
+// With returns a logger adding the given fields to all its entries. Fields
+// replace the ones of the logger under the same key.
+func (l *Logger) With(fields ...Field) *Logger {
+	if l == nil || len(fields) == 0 {
+		return l
+	}
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+outer:
+	for _, f := range fields {
+		for i := range all {
+			if all[i].Key == f.Key {
+				all[i] = f
+				continue outer
+			}
+		}
+		all = append(all, f)
+	}
+	return &Logger{out: l.out, component: l.component, fields: all}
+}
+
+// Fields returns the fields the logger adds to its entries.
+func (l *Logger) Fields() []Field {
+	if l == nil {
+		return nil
+	}
+	return append([]Field(nil), l.fields...)
+}
+
+// SetLevel sets the minimum level of the entries of the named component, or
+// of the components without a level of their own if the name is empty.
+func (l *Logger) SetLevel(component string, level Level) {
+	if l == nil {
+		return
+	}
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if component == "" {
+		l.out.level = level
+		return
+	}
+	l.out.levels[component] = level
+}
+
+// SetLevels sets the levels described by a comma separated list of levels
+// optionally prefixed by a component, e.g. "info,issue1=debug,session=warn".
+// Unprefixed levels apply to the components without a level of their own.
+func (l *Logger) SetLevels(spec string) error {
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		component, name := "", part
+		if eq := strings.Index(part, "="); eq >= 0 {
+			component, name = part[:eq], part[eq+1:]
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		l.SetLevel(component, level)
+	}
+	return nil
+}
+
+// Enabled reports whether entries of the given level are written.
+func (l *Logger) Enabled(level Level) bool {
+	if l == nil {
+		return false
+	}
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	return level >= l.out.levelOf(l.component)
+}
+
+func (o *output) levelOf(component string) Level {
+	if level, ok := o.levels[component]; ok {
+		return level
+	}
+	return o.level
+}
+
+// Debug writes an entry of level LevelDebug.
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.log(LevelDebug, msg, fields)
+}
+
+// Info writes an entry of level LevelInfo.
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.log(LevelInfo, msg, fields)
+}
+
+// Warn writes an entry of level LevelWarn.
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.log(LevelWarn, msg, fields)
+}
+
+// Error writes an entry of level LevelError.
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(LevelError, msg, fields)
+}
+
+// Fatal writes an entry of level LevelError and exits the program.
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.log(LevelError, msg, fields)
+	os.Exit(1)
+}
+
+func (l *Logger) log(level Level, msg string, fields []Field) {
+	if l == nil {
+		return
+	}
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if level < l.out.levelOf(l.component) {
+		return
+	}
+	entry := make([]Field, 0, 4+len(l.fields)+len(fields))
+	entry = append(entry, F("time", l.out.now().Format(timeFormat)), F("level", level.String()))
+	if l.component != "" {
+		entry = append(entry, F("component", l.component))
+	}
+	entry = append(entry, F("msg", msg))
+	entry = append(entry, l.fields...)
+	entry = append(entry, fields...)
+
+	var b strings.Builder
+	if l.out.format == FormatJSON {
+		encodeJSON(&b, entry)
+	} else {
+		encodeLogfmt(&b, entry)
+	}
+	b.WriteByte('\n')
+	// entries that fail to be written are dropped
+	_, _ = io.WriteString(l.out.w, b.String())
+}
+
+const timeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// value returns what's written of v.
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+func encodeJSON(b *strings.Builder, fields []Field) {
+	b.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Key)
+		b.Write(key)
+		b.WriteByte(':')
+		v, err := json.Marshal(value(f.Value))
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(f.Value))
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+}
+
+func encodeLogfmt(b *strings.Builder, fields []Field) {
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		s := fmt.Sprint(value(f.Value))
+		if s == "" || strings.ContainsAny(s, " =\"\\\n\t") {
+			s = fmt.Sprintf("%q", s)
+		}
+		b.WriteString(s)
+	}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLogger(format Format) (*Logger, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	l := New(buf, format)
+	l.out.now = func() time.Time { return time.Date(2020, 5, 17, 13, 4, 5, 0, time.UTC) }
+	return l, buf
+}
+
+func TestFormats(t *testing.T) {
+	for _, tc := range []struct {
+		format Format
+		want   string
+	}{
+		{FormatLogfmt, `time=2020-05-17T13:04:05.000000Z level=warn component=web msg="login failed" route="POST /login" username=loveless error="access denied" took=1.5s` + "\n"},
+		{FormatJSON, `{"time":"2020-05-17T13:04:05.000000Z","level":"warn","component":"web","msg":"login failed","route":"POST /login","username":"loveless","error":"access denied","took":"1.5s"}` + "\n"},
+	} {
+		l, buf := newTestLogger(tc.format)
+		l.Component("web").With(F("route", "POST /login")).Warn("login failed",
+			F("username", "loveless"), Err(errors.New("access denied")), F("took", 1500*time.Millisecond))
+		if got := buf.String(); got != tc.want {
+			t.Errorf("format %d wrote:\n%s\nwant:\n%s", tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	l, buf := newTestLogger(FormatLogfmt)
+	if err := l.SetLevels("warn, issue1=debug"); err != nil {
+		t.Fatal(err)
+	}
+	web, rest := l.Component("web"), l.Component("issue1")
+	web.Info("dropped")
+	rest.Debug("kept")
+	web.Error("kept")
+	if got := bytes.Count(buf.Bytes(), []byte("msg=kept")); got != 2 || bytes.Contains(buf.Bytes(), []byte("dropped")) {
+		t.Errorf("wrong entries written:\n%s", buf)
+	}
+	if err := l.SetLevels("issue1=loud"); err == nil {
+		t.Error("SetLevels accepted an unknown level")
+	}
+}
+
+func TestContext(t *testing.T) {
+	l, buf := newTestLogger(FormatLogfmt)
+	ctx := NewContext(context.Background(), l.With(F("request_id", "abc123")))
+	AddFields(ctx, F("username", "loveless"))
+	FromContext(ctx).Info("served")
+	if want := "request_id=abc123 username=loveless\n"; !bytes.HasSuffix(buf.Bytes(), []byte(want)) {
+		t.Errorf("wrote %q, want it to end with %q", buf, want)
+	}
+	if FromContext(context.Background()) != nil {
+		t.Error("FromContext returned a logger for an empty context")
+	}
+	// a nil logger discards everything
+	FromContext(context.Background()).With(F("a", 1)).Error("nothing")
+}
@@ -4,9 +4,9 @@ pushd ..
 echo -- build started
 go build -o ".build\issue1website.exe" -i -v "cmd\issue1.website\main.go"
 echo -- build completed
-echo -- enter "k" to stop the server.
-echo -- enter "r" to refresh templates from disk.
-.build\issue1website.exe
+echo -- press Ctrl+C to stop the server.
+echo -- templates are reloaded from disk as they're edited.
+.build\issue1website.exe -server.watch_templates=1s
 del .build\issue1website.exe
 popd
 popd
\ No newline at end of file